@@ -5,8 +5,8 @@ import (
 	"net/http"
 	"time"
 
-	db "github.com/Doraverse-Workspace/open-observe/example/mongo"
 	"github.com/Doraverse-Workspace/open-observe/example/mongo/model"
+	db "github.com/Doraverse-Workspace/open-observe/mongootel"
 	tracermodule "github.com/Doraverse-Workspace/open-observe/otel"
 	"github.com/labstack/echo/v4"
 	"go.mongodb.org/mongo-driver/bson"
@@ -31,7 +31,7 @@ func (h *UserHandler) CreateUser(c echo.Context) error {
 	tracerCtx := c.Get(tracermodule.TraceContextKey).(tracermodule.TraceContext)
 	err := tracermodule.StartSpan(tracerCtx, "CreateUser", func(ctx context.Context, span trace.Span) error {
 		if err := c.Bind(&user); err != nil {
-			span.RecordError(err)
+			tracermodule.RecordError(span, err, tracermodule.WithErrorKind(tracermodule.ErrorKindClient))
 			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 		}
 
@@ -42,7 +42,7 @@ func (h *UserHandler) CreateUser(c echo.Context) error {
 
 		_, err := h.collection.InsertOne(ctx, user)
 		if err != nil {
-			span.RecordError(err)
+			tracermodule.RecordError(span, err, tracermodule.WithErrorKind(tracermodule.ErrorKindServer))
 			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 		}
 
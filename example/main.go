@@ -3,11 +3,13 @@ package main
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/Doraverse-Workspace/open-observe/example/handler"
-	db "github.com/Doraverse-Workspace/open-observe/example/mongo"
+	db "github.com/Doraverse-Workspace/open-observe/mongootel"
 	tel "github.com/Doraverse-Workspace/open-observe/otel"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -29,6 +31,21 @@ func main() {
 			fmt.Println("Error shutting down tracer provider: ", err)
 		}
 	}()
+	mp := tel.InitMeterHTTP(config)
+	defer func() {
+		if err := mp.Shutdown(context.Background()); err != nil {
+			fmt.Println("Error shutting down meter provider: ", err)
+		}
+	}()
+	lp := tel.InitLoggerHTTP(config)
+	defer func() {
+		if err := lp.Shutdown(context.Background()); err != nil {
+			fmt.Println("Error shutting down logger provider: ", err)
+		}
+	}()
+	slog.SetDefault(slog.New(tel.NewSlogHandler(config, tel.SlogHandlerOptions{
+		Tee: os.Stdout,
+	})))
 
 	// Initialize MongoDB client with APM configuration
 	mongoConfig := db.DefaultConfig()
@@ -55,7 +72,7 @@ func main() {
 	e := echo.New()
 
 	// Middleware
-	e.Use(middleware.Logger())
+	e.Use(tel.RequestLoggerMiddleware(config))
 	e.Use(middleware.Recover())
 	e.Use(tel.OtelMiddleware(config))
 
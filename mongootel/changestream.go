@@ -0,0 +1,192 @@
+package mongootel
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ChangeStream wraps a MongoDB change stream with tracing. A MongoDB.Watch span
+// lasts until Close. Every event received gets its own MongoDB.ChangeEvent root
+// span, linked to the watch span, which lasts until the next call to Next, TryNext
+// or Close, so it covers the processing of the event. Use EventContext to parent
+// that processing.
+type ChangeStream struct {
+	*mongo.ChangeStream
+
+	tracer    trace.Tracer
+	span      trace.Span
+	attrs     []attribute.KeyValue
+	event     trace.Span
+	cursorID  int64
+	events    int64
+	resumes   int64
+	closeOnce sync.Once
+}
+
+// Watch opens a traced change stream on the collection, see mongo.Collection.Watch
+func (c *Collection) Watch(ctx context.Context, pipeline interface{}, opts ...*options.ChangeStreamOptions) (*ChangeStream, error) {
+	ctx, span := c.startSpan(ctx, "Watch")
+
+	stream, err := c.coll.Watch(ctx, pipeline, opts...)
+	return newChangeStream(c.tracer, span, stream, err,
+		attribute.String("db.system", "mongodb"),
+		attribute.String("db.name", c.coll.Database().Name()),
+		attribute.String("db.collection", c.coll.Name()),
+	)
+}
+
+// Watch opens a traced change stream on the whole deployment, see mongo.Client.Watch
+func (c *Client) Watch(ctx context.Context, pipeline interface{}, opts ...*options.ChangeStreamOptions) (*ChangeStream, error) {
+	ctx, span := c.startSpan(ctx, "Watch")
+	ctx = context.WithValue(ctx, operationSpanKey{}, &operationSpan{span: span})
+
+	stream, err := c.client.Watch(ctx, pipeline, opts...)
+	return newChangeStream(c.tracer, span, stream, err,
+		attribute.String("db.system", "mongodb"),
+	)
+}
+
+// newChangeStream wraps stream, the watch span is ended when opening the stream failed
+func newChangeStream(tracer trace.Tracer, span trace.Span, stream *mongo.ChangeStream, err error, attrs ...attribute.KeyValue) (*ChangeStream, error) {
+	if err != nil {
+		handleError(span, err)
+		span.End()
+		return nil, err
+	}
+
+	return &ChangeStream{
+		ChangeStream: stream,
+		tracer:       tracer,
+		span:         span,
+		attrs:        attrs,
+		cursorID:     stream.ID(),
+	}, nil
+}
+
+// Next waits for the next event, see mongo.ChangeStream.Next
+func (cs *ChangeStream) Next(ctx context.Context) bool {
+	return cs.advance(ctx, cs.ChangeStream.Next)
+}
+
+// TryNext gets the next event without waiting for one, see mongo.ChangeStream.TryNext
+func (cs *ChangeStream) TryNext(ctx context.Context) bool {
+	return cs.advance(ctx, cs.ChangeStream.TryNext)
+}
+
+// EventContext returns ctx with the span of the current event, so that the
+// processing of the event is part of its trace
+func (cs *ChangeStream) EventContext(ctx context.Context) context.Context {
+	if cs.event == nil {
+		return ctx
+	}
+	return trace.ContextWithSpan(ctx, cs.event)
+}
+
+// Close closes the change stream and ends its spans
+func (cs *ChangeStream) Close(ctx context.Context) error {
+	cs.endEvent()
+	err := cs.ChangeStream.Close(ctx)
+	cs.closeOnce.Do(func() {
+		cs.span.SetAttributes(
+			attribute.Int64("db.mongodb.change_stream.events", cs.events),
+			attribute.Int64("db.mongodb.change_stream.resumes", cs.resumes),
+		)
+		if streamErr := cs.Err(); streamErr != nil {
+			handleError(cs.span, streamErr)
+		} else {
+			handleError(cs.span, err)
+		}
+		cs.span.End()
+	})
+	return err
+}
+
+// advance ends the span of the previous event, calls next and starts the span of
+// the event it received
+func (cs *ChangeStream) advance(ctx context.Context, next func(context.Context) bool) bool {
+	cs.endEvent()
+
+	// Waiting for events polls the server with getMore, those are not traced
+	ok := next(withoutCommandSpans(ctx))
+	cs.checkResume()
+	if !ok {
+		if err := cs.Err(); err != nil {
+			cs.span.RecordError(err)
+		}
+		return false
+	}
+
+	cs.events++
+	cs.startEvent(ctx)
+	return true
+}
+
+// checkResume records a resumption: after a resumable error the driver opens a
+// new cursor from the last resume token
+func (cs *ChangeStream) checkResume() {
+	id := cs.ID()
+	if id == cs.cursorID || id == 0 {
+		return
+	}
+	if cs.cursorID != 0 {
+		cs.resumes++
+		attrs := []attribute.KeyValue{
+			attribute.Int64("db.mongodb.cursor_id", id),
+			attribute.Int64("db.mongodb.previous_cursor_id", cs.cursorID),
+		}
+		if token := cs.ResumeToken(); token != nil {
+			attrs = append(attrs, attribute.String("db.mongodb.change_stream.resume_token", token.String()))
+		}
+		cs.span.AddEvent("change_stream.resume", trace.WithAttributes(attrs...))
+	}
+	cs.cursorID = id
+}
+
+// startEvent starts the span of the current event
+func (cs *ChangeStream) startEvent(ctx context.Context) {
+	attrs := append([]attribute.KeyValue{}, cs.attrs...)
+	attrs = append(attrs, changeEventAttributes(cs.Current)...)
+	if token := cs.ResumeToken(); token != nil {
+		attrs = append(attrs, attribute.String("db.mongodb.change_stream.resume_token", token.String()))
+	}
+
+	_, cs.event = cs.tracer.Start(ctx, "MongoDB.ChangeEvent",
+		trace.WithNewRoot(),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithLinks(trace.Link{SpanContext: cs.span.SpanContext()}),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// endEvent ends the span of the current event
+func (cs *ChangeStream) endEvent() {
+	if cs.event != nil {
+		cs.event.End()
+		cs.event = nil
+	}
+}
+
+// changeEventAttributes returns the operation type, namespace and document key of
+// a change event
+func changeEventAttributes(event bson.Raw) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if operationType, ok := event.Lookup("operationType").StringValueOK(); ok {
+		attrs = append(attrs, attribute.String("db.mongodb.change_stream.operation_type", operationType))
+	}
+	if db, ok := event.Lookup("ns", "db").StringValueOK(); ok {
+		attrs = append(attrs, attribute.String("db.name", db))
+	}
+	if coll, ok := event.Lookup("ns", "coll").StringValueOK(); ok {
+		attrs = append(attrs, attribute.String("db.collection", coll))
+	}
+	if key, err := event.LookupErr("documentKey"); err == nil {
+		attrs = append(attrs, attribute.String("db.mongodb.change_stream.document_key", key.String()))
+	}
+	return attrs
+}
@@ -0,0 +1,83 @@
+package mongootel_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Doraverse-Workspace/open-observe/mongootel"
+	"github.com/Doraverse-Workspace/open-observe/oteltest"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// changeEvent is an insert event of the users collection
+func changeEvent(token string, id int) bson.D {
+	return bson.D{
+		{Key: "_id", Value: bson.D{{Key: "_data", Value: token}}},
+		{Key: "operationType", Value: "insert"},
+		{Key: "ns", Value: bson.D{{Key: "db", Value: "test"}, {Key: "coll", Value: "users"}}},
+		{Key: "documentKey", Value: bson.D{{Key: "_id", Value: id}}},
+		{Key: "fullDocument", Value: bson.D{{Key: "_id", Value: id}, {Key: "name", Value: "ada"}}},
+	}
+}
+
+func TestChangeStreamEventSpans(t *testing.T) {
+	r := oteltest.NewLocal(t)
+	server := newFakeServer(t)
+	server.Handle("aggregate", func(bson.Raw) bson.D {
+		return cursorReply("firstBatch", 7)
+	})
+	server.HandleSequence("getMore",
+		cursorReply("nextBatch", 7, changeEvent("01", 1)),
+		cursorReply("nextBatch", 7, changeEvent("02", 2)),
+	)
+	client := newTestClient(t, server, mongootel.WithTracerProvider(r.TracerProvider))
+	r.Reset()
+
+	ctx := context.Background()
+	stream, err := client.Collection("users").Watch(ctx, bson.A{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var processed []trace.SpanContext
+	for i := 0; i < 2 && stream.Next(ctx); i++ {
+		_, span := r.Tracer("test").Start(stream.EventContext(ctx), "process")
+		processed = append(processed, span.SpanContext())
+		span.End()
+	}
+	if err := stream.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := r.Spans()
+	watch := spans.Find("MongoDB.Watch").
+		IsRoot().
+		HasAttribute("db.command", "aggregate").
+		HasAttribute("db.mongodb.change_stream.events", 2).
+		HasStatus(codes.Ok)
+	events := spans.Named("MongoDB.ChangeEvent")
+	if events.Len() != 2 {
+		t.Fatalf("%d MongoDB.ChangeEvent spans, want 2", events.Len())
+	}
+	for i, event := range events.All() {
+		spans.Assert(event).
+			IsRoot().
+			HasKind(trace.SpanKindConsumer).
+			HasAttribute("db.collection", "users").
+			HasAttribute("db.mongodb.change_stream.operation_type", "insert").
+			HasAttributeKey("db.mongodb.change_stream.document_key").
+			HasChild("process")
+		if links := event.Links(); len(links) != 1 || links[0].SpanContext.SpanID() != watch.Span().SpanContext().SpanID() {
+			t.Errorf("MongoDB.ChangeEvent links = %v, want a link to MongoDB.Watch", links)
+		}
+		if event.SpanContext().TraceID() != processed[i].TraceID() {
+			t.Errorf("event %d was processed in another trace", i)
+		}
+	}
+
+	// The getMore polls of the stream are not traced
+	if n := spans.Named("MongoDB.getMore").Len(); n != 0 {
+		t.Errorf("%d MongoDB.getMore spans, want 0", n)
+	}
+}
@@ -0,0 +1,273 @@
+package mongootel
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Collection wraps MongoDB collection with tracing
+type Collection struct {
+	coll     *mongo.Collection
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+}
+
+// Driver returns the underlying driver collection, its operations are not traced
+func (c *Collection) Driver() *mongo.Collection {
+	return c.coll
+}
+
+// InsertOne inserts a document with automatic span creation
+func (c *Collection) InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+	ctx, span := c.startSpan(ctx, "InsertOne")
+	defer span.End()
+	defer c.recordDuration(ctx, "InsertOne", time.Now())
+
+	result, err := c.coll.InsertOne(ctx, document, opts...)
+	if result != nil {
+		span.SetAttributes(attribute.Int("db.mongodb.inserted_count", 1))
+	}
+	handleError(span, err)
+	return result, err
+}
+
+// FindOne finds a single document with automatic span creation
+func (c *Collection) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) *mongo.SingleResult {
+	ctx, span := c.startSpan(ctx, "FindOne")
+	defer span.End()
+	defer c.recordDuration(ctx, "FindOne", time.Now())
+
+	result := c.coll.FindOne(ctx, filter, opts...)
+	handleError(span, result.Err())
+	return result
+}
+
+// Find finds multiple documents with automatic span creation. The returned cursor
+// is traced until it is closed.
+func (c *Collection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (*Cursor, error) {
+	parent := ctx
+	ctx, span := c.startSpan(ctx, "Find")
+	defer span.End()
+	defer c.recordDuration(ctx, "Find", time.Now())
+
+	cursor, err := c.coll.Find(ctx, filter, opts...)
+	handleError(span, err)
+	return c.newCursor(parent, span, "Find", cursor), err
+}
+
+// UpdateOne updates a single document with automatic span creation
+func (c *Collection) UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	ctx, span := c.startSpan(ctx, "UpdateOne")
+	defer span.End()
+	defer c.recordDuration(ctx, "UpdateOne", time.Now())
+
+	result, err := c.coll.UpdateOne(ctx, filter, update, opts...)
+	setUpdateResult(span, result)
+	handleError(span, err)
+	return result, err
+}
+
+// DeleteOne deletes a single document with automatic span creation
+func (c *Collection) DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	ctx, span := c.startSpan(ctx, "DeleteOne")
+	defer span.End()
+	defer c.recordDuration(ctx, "DeleteOne", time.Now())
+
+	result, err := c.coll.DeleteOne(ctx, filter, opts...)
+	setDeleteResult(span, result)
+	handleError(span, err)
+	return result, err
+}
+
+// InsertMany inserts documents with automatic span creation
+func (c *Collection) InsertMany(ctx context.Context, documents []interface{}, opts ...*options.InsertManyOptions) (*mongo.InsertManyResult, error) {
+	ctx, span := c.startSpan(ctx, "InsertMany")
+	defer span.End()
+	defer c.recordDuration(ctx, "InsertMany", time.Now())
+
+	span.SetAttributes(attribute.Int("db.mongodb.document_count", len(documents)))
+	result, err := c.coll.InsertMany(ctx, documents, opts...)
+	if result != nil {
+		span.SetAttributes(attribute.Int("db.mongodb.inserted_count", len(result.InsertedIDs)))
+	}
+	handleError(span, err)
+	return result, err
+}
+
+// UpdateMany updates all matching documents with automatic span creation
+func (c *Collection) UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	ctx, span := c.startSpan(ctx, "UpdateMany")
+	defer span.End()
+	defer c.recordDuration(ctx, "UpdateMany", time.Now())
+
+	result, err := c.coll.UpdateMany(ctx, filter, update, opts...)
+	setUpdateResult(span, result)
+	handleError(span, err)
+	return result, err
+}
+
+// ReplaceOne replaces a single document with automatic span creation
+func (c *Collection) ReplaceOne(ctx context.Context, filter interface{}, replacement interface{}, opts ...*options.ReplaceOptions) (*mongo.UpdateResult, error) {
+	ctx, span := c.startSpan(ctx, "ReplaceOne")
+	defer span.End()
+	defer c.recordDuration(ctx, "ReplaceOne", time.Now())
+
+	result, err := c.coll.ReplaceOne(ctx, filter, replacement, opts...)
+	setUpdateResult(span, result)
+	handleError(span, err)
+	return result, err
+}
+
+// DeleteMany deletes all matching documents with automatic span creation
+func (c *Collection) DeleteMany(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	ctx, span := c.startSpan(ctx, "DeleteMany")
+	defer span.End()
+	defer c.recordDuration(ctx, "DeleteMany", time.Now())
+
+	result, err := c.coll.DeleteMany(ctx, filter, opts...)
+	setDeleteResult(span, result)
+	handleError(span, err)
+	return result, err
+}
+
+// FindOneAndUpdate updates a single document and returns it with automatic span creation
+func (c *Collection) FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, opts ...*options.FindOneAndUpdateOptions) *mongo.SingleResult {
+	ctx, span := c.startSpan(ctx, "FindOneAndUpdate")
+	defer span.End()
+	defer c.recordDuration(ctx, "FindOneAndUpdate", time.Now())
+
+	result := c.coll.FindOneAndUpdate(ctx, filter, update, opts...)
+	handleError(span, result.Err())
+	return result
+}
+
+// FindOneAndReplace replaces a single document and returns it with automatic span creation
+func (c *Collection) FindOneAndReplace(ctx context.Context, filter interface{}, replacement interface{}, opts ...*options.FindOneAndReplaceOptions) *mongo.SingleResult {
+	ctx, span := c.startSpan(ctx, "FindOneAndReplace")
+	defer span.End()
+	defer c.recordDuration(ctx, "FindOneAndReplace", time.Now())
+
+	result := c.coll.FindOneAndReplace(ctx, filter, replacement, opts...)
+	handleError(span, result.Err())
+	return result
+}
+
+// FindOneAndDelete deletes a single document and returns it with automatic span creation
+func (c *Collection) FindOneAndDelete(ctx context.Context, filter interface{}, opts ...*options.FindOneAndDeleteOptions) *mongo.SingleResult {
+	ctx, span := c.startSpan(ctx, "FindOneAndDelete")
+	defer span.End()
+	defer c.recordDuration(ctx, "FindOneAndDelete", time.Now())
+
+	result := c.coll.FindOneAndDelete(ctx, filter, opts...)
+	handleError(span, result.Err())
+	return result
+}
+
+// Aggregate runs an aggregation pipeline with automatic span creation. The returned
+// cursor is traced until it is closed.
+func (c *Collection) Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (*Cursor, error) {
+	parent := ctx
+	ctx, span := c.startSpan(ctx, "Aggregate")
+	defer span.End()
+	defer c.recordDuration(ctx, "Aggregate", time.Now())
+
+	cursor, err := c.coll.Aggregate(ctx, pipeline, opts...)
+	handleError(span, err)
+	return c.newCursor(parent, span, "Aggregate", cursor), err
+}
+
+// BulkWrite runs write operations in bulk with automatic span creation
+func (c *Collection) BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+	ctx, span := c.startSpan(ctx, "BulkWrite")
+	defer span.End()
+	defer c.recordDuration(ctx, "BulkWrite", time.Now())
+
+	span.SetAttributes(attribute.Int("db.mongodb.model_count", len(models)))
+	result, err := c.coll.BulkWrite(ctx, models, opts...)
+	if result != nil {
+		span.SetAttributes(
+			attribute.Int64("db.mongodb.inserted_count", result.InsertedCount),
+			attribute.Int64("db.mongodb.matched_count", result.MatchedCount),
+			attribute.Int64("db.mongodb.modified_count", result.ModifiedCount),
+			attribute.Int64("db.mongodb.deleted_count", result.DeletedCount),
+			attribute.Int64("db.mongodb.upserted_count", result.UpsertedCount),
+		)
+	}
+	handleError(span, err)
+	return result, err
+}
+
+// CountDocuments counts the matching documents with automatic span creation
+func (c *Collection) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	ctx, span := c.startSpan(ctx, "CountDocuments")
+	defer span.End()
+	defer c.recordDuration(ctx, "CountDocuments", time.Now())
+
+	count, err := c.coll.CountDocuments(ctx, filter, opts...)
+	if err == nil {
+		span.SetAttributes(attribute.Int64("db.mongodb.count", count))
+	}
+	handleError(span, err)
+	return count, err
+}
+
+// EstimatedDocumentCount estimates the number of documents from the collection
+// metadata with automatic span creation
+func (c *Collection) EstimatedDocumentCount(ctx context.Context, opts ...*options.EstimatedDocumentCountOptions) (int64, error) {
+	ctx, span := c.startSpan(ctx, "EstimatedDocumentCount")
+	defer span.End()
+	defer c.recordDuration(ctx, "EstimatedDocumentCount", time.Now())
+
+	count, err := c.coll.EstimatedDocumentCount(ctx, opts...)
+	if err == nil {
+		span.SetAttributes(attribute.Int64("db.mongodb.count", count))
+	}
+	handleError(span, err)
+	return count, err
+}
+
+// Distinct returns the distinct values of a field with automatic span creation
+func (c *Collection) Distinct(ctx context.Context, fieldName string, filter interface{}, opts ...*options.DistinctOptions) ([]interface{}, error) {
+	ctx, span := c.startSpan(ctx, "Distinct")
+	defer span.End()
+	defer c.recordDuration(ctx, "Distinct", time.Now())
+
+	span.SetAttributes(attribute.String("db.mongodb.field", fieldName))
+	values, err := c.coll.Distinct(ctx, fieldName, filter, opts...)
+	if err == nil {
+		span.SetAttributes(attribute.Int("db.mongodb.count", len(values)))
+	}
+	handleError(span, err)
+	return values, err
+}
+
+// Indexes returns the traced index view of the collection
+func (c *Collection) Indexes() IndexView {
+	return IndexView{view: c.coll.Indexes(), coll: c}
+}
+
+// setUpdateResult adds the counts of an update or replace to the span
+func setUpdateResult(span trace.Span, result *mongo.UpdateResult) {
+	if result == nil {
+		return
+	}
+	span.SetAttributes(
+		attribute.Int64("db.mongodb.matched_count", result.MatchedCount),
+		attribute.Int64("db.mongodb.modified_count", result.ModifiedCount),
+		attribute.Int64("db.mongodb.upserted_count", result.UpsertedCount),
+	)
+}
+
+// setDeleteResult adds the count of a delete to the span
+func setDeleteResult(span trace.Span, result *mongo.DeleteResult) {
+	if result == nil {
+		return
+	}
+	span.SetAttributes(attribute.Int64("db.mongodb.deleted_count", result.DeletedCount))
+}
@@ -0,0 +1,156 @@
+package mongootel
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Cursor wraps a MongoDB cursor with tracing. A MongoDB.Cursor span covers the
+// cursor from the query until it is exhausted, fails or is closed, and records
+// the documents, batches and bytes read and the time spent iterating. The span is
+// a sibling of the query span, which has already ended, and links to it. getMore
+// commands are children of the cursor span.
+type Cursor struct {
+	*mongo.Cursor
+
+	span      trace.Span
+	start     time.Time
+	documents int64
+	batches   int64
+	bytes     int64
+	iterating time.Duration
+	firstDoc  time.Duration
+	closeOnce sync.Once
+}
+
+// newCursor starts the span of cursor under ctx, the context of the caller, linked
+// to the query span. A nil cursor is returned as nil.
+func (c *Collection) newCursor(ctx context.Context, query trace.Span, operation string, cursor *mongo.Cursor) *Cursor {
+	if cursor == nil {
+		return nil
+	}
+
+	_, span := c.tracer.Start(ctx, "MongoDB.Cursor",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithLinks(trace.Link{SpanContext: query.SpanContext()}),
+		trace.WithAttributes(
+			attribute.String("db.system", "mongodb"),
+			attribute.String("db.name", c.coll.Database().Name()),
+			attribute.String("db.collection", c.coll.Name()),
+			attribute.String("db.operation", operation),
+			attribute.Int64("db.mongodb.cursor_id", cursor.ID()),
+		),
+	)
+
+	tc := &Cursor{Cursor: cursor, span: span, start: time.Now()}
+	if cursor.RemainingBatchLength() > 0 {
+		tc.batches = 1
+	}
+	return tc
+}
+
+// Next gets the next document, see mongo.Cursor.Next
+func (c *Cursor) Next(ctx context.Context) bool {
+	return c.advance(ctx, c.Cursor.Next)
+}
+
+// TryNext gets the next document without blocking for a new batch of a tailable
+// cursor, see mongo.Cursor.TryNext
+func (c *Cursor) TryNext(ctx context.Context) bool {
+	return c.advance(ctx, c.Cursor.TryNext)
+}
+
+// All decodes every remaining document into results, a pointer to a slice, and
+// closes the cursor, see mongo.Cursor.All
+func (c *Cursor) All(ctx context.Context, results interface{}) error {
+	defer c.Close(ctx)
+
+	resultsVal := reflect.ValueOf(results)
+	if resultsVal.Kind() != reflect.Ptr || resultsVal.Elem().Kind() != reflect.Slice {
+		return errors.New("results argument must be a pointer to a slice")
+	}
+	sliceVal := resultsVal.Elem()
+	if sliceVal.IsNil() {
+		sliceVal = reflect.MakeSlice(sliceVal.Type(), 0, 0)
+	}
+	sliceVal = sliceVal.Slice(0, 0)
+
+	elemType := sliceVal.Type().Elem()
+	for c.Next(ctx) {
+		elem := reflect.New(elemType)
+		if err := c.Decode(elem.Interface()); err != nil {
+			c.span.RecordError(err)
+			return err
+		}
+		sliceVal = reflect.Append(sliceVal, elem.Elem())
+	}
+	resultsVal.Elem().Set(sliceVal)
+	return c.Err()
+}
+
+// Close closes the cursor and ends its span
+func (c *Cursor) Close(ctx context.Context) error {
+	err := c.Cursor.Close(ctx)
+	c.finish("cursor.close", err)
+	return err
+}
+
+// finish adds event to the span and ends it once, with the cursor error or else
+// err as its status
+func (c *Cursor) finish(event string, err error) {
+	c.closeOnce.Do(func() {
+		attrs := []attribute.KeyValue{
+			attribute.Int64("db.mongodb.cursor.documents", c.documents),
+			attribute.Int64("db.mongodb.cursor.batches", c.batches),
+			attribute.Int64("db.mongodb.cursor.bytes", c.bytes),
+			attribute.Float64("db.mongodb.cursor.iteration_ms", float64(c.iterating.Microseconds())/1000),
+			attribute.Float64("db.mongodb.cursor.open_ms", float64(time.Since(c.start).Microseconds())/1000),
+		}
+		if c.documents > 0 {
+			attrs = append(attrs, attribute.Float64("db.mongodb.cursor.first_document_ms", float64(c.firstDoc.Microseconds())/1000))
+		}
+		c.span.SetAttributes(attrs...)
+		c.span.AddEvent(event)
+
+		if cursorErr := c.Err(); cursorErr != nil {
+			handleError(c.span, cursorErr)
+		} else {
+			handleError(c.span, err)
+		}
+		c.span.End()
+	})
+}
+
+// advance calls next with the cursor span in the context and counts what it read
+func (c *Cursor) advance(ctx context.Context, next func(context.Context) bool) bool {
+	// The batch is exhausted, a successful next fetches a new one
+	fetches := c.Cursor.RemainingBatchLength() == 0
+
+	start := time.Now()
+	ok := next(trace.ContextWithSpan(ctx, c.span))
+	c.iterating += time.Since(start)
+	if !ok {
+		// The server closes an exhausted cursor, a tailable one stays open
+		if c.Cursor.ID() == 0 || c.Err() != nil {
+			c.finish("cursor.exhausted", nil)
+		}
+		return false
+	}
+
+	if fetches {
+		c.batches++
+	}
+	if c.documents == 0 {
+		c.firstDoc = time.Since(c.start)
+	}
+	c.documents++
+	c.bytes += int64(len(c.Current))
+	return true
+}
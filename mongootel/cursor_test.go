@@ -0,0 +1,141 @@
+package mongootel_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Doraverse-Workspace/open-observe/mongootel"
+	"github.com/Doraverse-Workspace/open-observe/oteltest"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestCursorSpanEndsWhenExhausted(t *testing.T) {
+	r := oteltest.NewLocal(t)
+	server := newFakeServer(t)
+	server.Handle("find", func(bson.Raw) bson.D {
+		return cursorReply("firstBatch", 42, bson.D{{Key: "_id", Value: 1}}, bson.D{{Key: "_id", Value: 2}})
+	})
+	server.Handle("getMore", func(bson.Raw) bson.D {
+		return cursorReply("nextBatch", 0, bson.D{{Key: "_id", Value: 3}})
+	})
+	client := newTestClient(t, server, mongootel.WithTracerProvider(r.TracerProvider))
+	r.Reset()
+
+	ctx, parent := r.Tracer("test").Start(context.Background(), "parent")
+	cursor, err := client.Collection("users").Find(ctx, bson.M{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	documents := 0
+	for cursor.Next(ctx) {
+		documents++
+	}
+	parent.End()
+	if documents != 3 {
+		t.Fatalf("cursor returned %d documents, want 3", documents)
+	}
+
+	// The span ended without Close
+	spans := r.Spans()
+	find := spans.Find("MongoDB.Find").HasParent("parent")
+	cursorSpan := spans.Find("MongoDB.Cursor").
+		HasParent("parent").
+		HasAttribute("db.operation", "Find").
+		HasAttribute("db.mongodb.cursor_id", 42).
+		HasAttribute("db.mongodb.cursor.documents", 3).
+		HasAttribute("db.mongodb.cursor.batches", 2).
+		HasEvent("cursor.exhausted").
+		HasStatus(codes.Ok).
+		HasChild("MongoDB.getMore")
+	links := cursorSpan.Span().Links()
+	if len(links) != 1 || links[0].SpanContext.SpanID() != find.Span().SpanContext().SpanID() {
+		t.Errorf("MongoDB.Cursor links = %v, want a link to MongoDB.Find", links)
+	}
+
+	if err := cursor.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := r.Spans().Named("MongoDB.Cursor").Len(); n != 1 {
+		t.Errorf("%d MongoDB.Cursor spans after Close, want 1", n)
+	}
+}
+
+func TestCursorSpanEndsOnError(t *testing.T) {
+	r := oteltest.NewLocal(t)
+	server := newFakeServer(t)
+	server.Handle("find", func(bson.Raw) bson.D {
+		return cursorReply("firstBatch", 42, bson.D{{Key: "_id", Value: 1}})
+	})
+	server.Handle("getMore", func(bson.Raw) bson.D {
+		return bson.D{{Key: "ok", Value: 0}, {Key: "code", Value: 43}, {Key: "codeName", Value: "CursorNotFound"}, {Key: "errmsg", Value: "cursor id 42 not found"}}
+	})
+	client := newTestClient(t, server, mongootel.WithTracerProvider(r.TracerProvider))
+	r.Reset()
+
+	cursor, err := client.Collection("users").Find(context.Background(), bson.M{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for cursor.Next(context.Background()) {
+	}
+	if cursor.Err() == nil {
+		t.Fatal("cursor succeeded, want the scripted getMore error")
+	}
+
+	r.Find("MongoDB.Cursor").
+		HasAttribute("db.mongodb.cursor.documents", 1).
+		HasStatus(codes.Error, cursor.Err().Error())
+}
+
+func TestCursorAll(t *testing.T) {
+	r := oteltest.NewLocal(t)
+	server := newFakeServer(t)
+	server.Handle("aggregate", func(bson.Raw) bson.D {
+		return cursorReply("firstBatch", 0, bson.D{{Key: "_id", Value: 1}}, bson.D{{Key: "_id", Value: 2}})
+	})
+	client := newTestClient(t, server, mongootel.WithTracerProvider(r.TracerProvider))
+	r.Reset()
+
+	cursor, err := client.Collection("users").Aggregate(context.Background(), bson.A{bson.M{"$match": bson.M{}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results []bson.M
+	if err := cursor.All(context.Background(), &results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("All() decoded %d documents, want 2", len(results))
+	}
+
+	r.Find("MongoDB.Cursor").
+		IsRoot().
+		HasAttribute("db.operation", "Aggregate").
+		HasAttribute("db.mongodb.cursor.documents", 2).
+		HasAttribute("db.mongodb.cursor.batches", 1).
+		HasEvent("cursor.exhausted")
+}
+
+func TestIndexesListCursor(t *testing.T) {
+	r := oteltest.NewLocal(t)
+	server := newFakeServer(t)
+	server.Handle("listIndexes", func(bson.Raw) bson.D {
+		return cursorReply("firstBatch", 0, bson.D{{Key: "name", Value: "_id_"}, {Key: "key", Value: bson.D{{Key: "_id", Value: 1}}}})
+	})
+	client := newTestClient(t, server, mongootel.WithTracerProvider(r.TracerProvider))
+	r.Reset()
+
+	cursor, err := client.Collection("users").Indexes().List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var indexes []bson.M
+	if err := cursor.All(context.Background(), &indexes); err != nil {
+		t.Fatal(err)
+	}
+
+	r.Find("MongoDB.Cursor").
+		HasAttribute("db.operation", "Indexes.List").
+		HasAttribute("db.mongodb.cursor.documents", 1)
+}
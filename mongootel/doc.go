@@ -0,0 +1,7 @@
+// Package mongootel traces and measures MongoDB operations made with the official
+// driver. Create a Client with NewClient and use its Collection wrappers in place
+// of the driver ones: every operation, command, cursor, transaction and change
+// stream is traced, and operation durations, slow operations and the connection
+// pool are recorded as metrics. Tracer and meter providers default to the global
+// ones and can be set with WithTracerProvider and WithMeterProvider.
+package mongootel
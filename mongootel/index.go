@@ -0,0 +1,81 @@
+package mongootel
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// IndexView wraps the index view of a collection with tracing
+type IndexView struct {
+	view mongo.IndexView
+	coll *Collection
+}
+
+// List lists the indexes of the collection with automatic span creation. The
+// returned cursor is traced until it is closed.
+func (iv IndexView) List(ctx context.Context, opts ...*options.ListIndexesOptions) (*Cursor, error) {
+	parent := ctx
+	ctx, span := iv.coll.startSpan(ctx, "Indexes.List")
+	defer span.End()
+	defer iv.coll.recordDuration(ctx, "Indexes.List", time.Now())
+
+	cursor, err := iv.view.List(ctx, opts...)
+	handleError(span, err)
+	return iv.coll.newCursor(parent, span, "Indexes.List", cursor), err
+}
+
+// CreateOne creates an index with automatic span creation
+func (iv IndexView) CreateOne(ctx context.Context, model mongo.IndexModel, opts ...*options.CreateIndexesOptions) (string, error) {
+	ctx, span := iv.coll.startSpan(ctx, "Indexes.CreateOne")
+	defer span.End()
+	defer iv.coll.recordDuration(ctx, "Indexes.CreateOne", time.Now())
+
+	name, err := iv.view.CreateOne(ctx, model, opts...)
+	if err == nil {
+		span.SetAttributes(attribute.String("db.mongodb.index", name))
+	}
+	handleError(span, err)
+	return name, err
+}
+
+// CreateMany creates indexes with automatic span creation
+func (iv IndexView) CreateMany(ctx context.Context, models []mongo.IndexModel, opts ...*options.CreateIndexesOptions) ([]string, error) {
+	ctx, span := iv.coll.startSpan(ctx, "Indexes.CreateMany")
+	defer span.End()
+	defer iv.coll.recordDuration(ctx, "Indexes.CreateMany", time.Now())
+
+	names, err := iv.view.CreateMany(ctx, models, opts...)
+	if err == nil {
+		span.SetAttributes(attribute.StringSlice("db.mongodb.index", names))
+	}
+	handleError(span, err)
+	return names, err
+}
+
+// DropOne drops an index by name with automatic span creation
+func (iv IndexView) DropOne(ctx context.Context, name string, opts ...*options.DropIndexesOptions) (bson.Raw, error) {
+	ctx, span := iv.coll.startSpan(ctx, "Indexes.DropOne")
+	defer span.End()
+	defer iv.coll.recordDuration(ctx, "Indexes.DropOne", time.Now())
+
+	span.SetAttributes(attribute.String("db.mongodb.index", name))
+	result, err := iv.view.DropOne(ctx, name, opts...)
+	handleError(span, err)
+	return result, err
+}
+
+// DropAll drops all indexes except _id with automatic span creation
+func (iv IndexView) DropAll(ctx context.Context, opts ...*options.DropIndexesOptions) (bson.Raw, error) {
+	ctx, span := iv.coll.startSpan(ctx, "Indexes.DropAll")
+	defer span.End()
+	defer iv.coll.recordDuration(ctx, "Indexes.DropAll", time.Now())
+
+	result, err := iv.view.DropAll(ctx, opts...)
+	handleError(span, err)
+	return result, err
+}
@@ -0,0 +1,225 @@
+package mongootel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tel "github.com/Doraverse-Workspace/open-observe/otel"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Config represents MongoDB configuration
+type Config struct {
+	URI             string
+	Database        string
+	Username        string
+	Password        string
+	MinPoolSize     uint64
+	MaxPoolSize     uint64
+	MaxConnIdleTime time.Duration
+	Timeout         time.Duration
+	APMConfig       APMConfig
+}
+
+// APMConfig represents Application Performance Monitoring configuration
+type APMConfig struct {
+	SlowOperationThreshold      time.Duration // Commands slower than this are flagged with db.slow, logged and counted, requires EnableCommandMonitor
+	EnableCommandMonitor        bool          // Enable command monitoring
+	EnablePoolMonitor           bool          // Enable connection pool metrics, events are logged with slog at debug level
+	MaxStatementLength          int           // Maximum length of db.statement, longer statements are truncated
+	DisableStatementCollections []string      // Collections whose statements are never recorded, e.g. ones holding secrets
+	ExplainSlowOperations       bool          // Explain slow read, update and delete commands in the background, record their plan on a linked span and log it
+	MaxExplainsPerMinute        int           // Rate limit of the explain commands, 6 when not set
+}
+
+// DefaultConfig returns a default configuration
+func DefaultConfig() Config {
+	return Config{
+		URI:             "mongodb://localhost:27017",
+		Database:        "test",
+		MinPoolSize:     5,
+		MaxPoolSize:     100,
+		MaxConnIdleTime: 5 * time.Minute,
+		Timeout:         10 * time.Second,
+		APMConfig: APMConfig{
+			SlowOperationThreshold: 100 * time.Millisecond,
+			EnableCommandMonitor:   true,
+			EnablePoolMonitor:      true,
+			MaxStatementLength:     defaultMaxStatementLength,
+		},
+	}
+}
+
+// Client wraps the MongoDB client with tracing capabilities
+type Client struct {
+	client   *mongo.Client
+	database string
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+	// slow is nil when slow operations are not tracked
+	slow *slowOperations
+}
+
+// NewClient creates a new MongoDB client with tracing and monitoring
+func NewClient(ctx context.Context, cfg Config, opts ...Option) (*Client, error) {
+	o := newClientOptions(opts)
+	tracer := o.tracerProvider.Tracer(ScopeName)
+	meter := o.meterProvider.Meter(ScopeName)
+
+	// Create MongoDB client options
+	clientOptions := options.Client().
+		ApplyURI(cfg.URI).
+		SetMinPoolSize(cfg.MinPoolSize).
+		SetMaxPoolSize(cfg.MaxPoolSize).
+		SetMaxConnIdleTime(cfg.MaxConnIdleTime)
+
+	if cfg.Username != "" && cfg.Password != "" {
+		clientOptions.SetAuth(options.Credential{
+			Username: cfg.Username,
+			Password: cfg.Password,
+		})
+	}
+
+	// Configure command monitoring if enabled
+	var commands *commandTracer
+	if cfg.APMConfig.EnableCommandMonitor {
+		var err error
+		commands, err = newCommandTracer(tracer, meter, cfg.APMConfig)
+		if err != nil {
+			return nil, err
+		}
+		clientOptions.SetMonitor(commands.monitor())
+	}
+
+	// Configure connection pool metrics if enabled
+	if cfg.APMConfig.EnablePoolMonitor {
+		poolMonitor, err := newPoolMonitor(meter)
+		if err != nil {
+			return nil, err
+		}
+		clientOptions.SetPoolMonitor(poolMonitor)
+	}
+
+	// Connect to MongoDB with timeout
+	timeoutCtx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+
+	client, err := mongo.Connect(timeoutCtx, clientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+
+	// Ping the database to verify connection
+	if err := client.Ping(timeoutCtx, nil); err != nil {
+		_ = client.Disconnect(context.WithoutCancel(ctx))
+		return nil, fmt.Errorf("failed to ping MongoDB: %w", err)
+	}
+
+	var slow *slowOperations
+	if commands != nil && commands.slow != nil {
+		slow = commands.slow
+		slow.client.Store(client)
+	}
+
+	duration, err := meter.Float64Histogram(
+		"db.client.operation.duration",
+		metric.WithDescription("Duration of MongoDB operations."),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10),
+	)
+	if err != nil {
+		_ = client.Disconnect(context.WithoutCancel(ctx))
+		return nil, fmt.Errorf("failed to create MongoDB duration histogram: %w", err)
+	}
+
+	return &Client{
+		client:   client,
+		database: cfg.Database,
+		tracer:   tracer,
+		duration: duration,
+		slow:     slow,
+	}, nil
+}
+
+// Collection returns a MongoDB collection with tracing wrapper
+func (c *Client) Collection(name string) *Collection {
+	return &Collection{
+		coll:     c.client.Database(c.database).Collection(name),
+		tracer:   c.tracer,
+		duration: c.duration,
+	}
+}
+
+// Driver returns the underlying driver client, its operations are not traced
+func (c *Client) Driver() *mongo.Client {
+	return c.client
+}
+
+// Close cancels the running explains of slow operations, waits for them and
+// disconnects from MongoDB
+func (c *Client) Close(ctx context.Context) error {
+	c.slow.close()
+	return c.client.Disconnect(ctx)
+}
+
+// startSpan starts the span of a client level operation, such as a transaction
+func (c *Client) startSpan(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return c.tracer.Start(ctx, fmt.Sprintf("MongoDB.%s", operation),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "mongodb"),
+			attribute.String("db.name", c.database),
+			attribute.String("db.operation", operation),
+		),
+	)
+}
+
+// recordDuration records the duration of a client level operation
+func (c *Client) recordDuration(ctx context.Context, operation string, start time.Time) {
+	c.duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+		attribute.String("db.system", "mongodb"),
+		attribute.String("db.operation", operation),
+	))
+}
+
+// startSpan starts a new span for MongoDB operation. The span is marked in the
+// returned context so the command monitor records the command on it.
+func (c *Collection) startSpan(ctx context.Context, operation string) (context.Context, trace.Span) {
+	ctx, span := c.tracer.Start(ctx, fmt.Sprintf("MongoDB.%s", operation),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "mongodb"),
+			attribute.String("db.name", c.coll.Database().Name()),
+			attribute.String("db.collection", c.coll.Name()),
+			attribute.String("db.operation", operation),
+		),
+	)
+	return context.WithValue(ctx, operationSpanKey{}, &operationSpan{span: span}), span
+}
+
+// recordDuration records the operation duration. ctx must carry the operation span
+// so that the exemplar links the measurement to the trace.
+func (c *Collection) recordDuration(ctx context.Context, operation string, start time.Time) {
+	c.duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+		attribute.String("db.system", "mongodb"),
+		attribute.String("db.collection", c.coll.Name()),
+		attribute.String("db.operation", operation),
+	))
+}
+
+// handleError handles error and sets span status. Errors the registered
+// ErrorClassifier ignores, such as mongo.ErrNoDocuments, leave the span successful.
+func handleError(span trace.Span, err error) {
+	if err == nil || tel.ClassifyError(err, 0) == tel.ErrorClassIgnore {
+		span.SetStatus(codes.Ok, "")
+		return
+	}
+	span.SetStatus(codes.Error, err.Error())
+	span.RecordError(err)
+}
@@ -0,0 +1,178 @@
+package mongootel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// operationSpanKey is the context key of the span started by a Collection method
+type operationSpanKey struct{}
+
+// noCommandSpansKey is the context key marking commands the monitor must not trace
+type noCommandSpansKey struct{}
+
+// withoutCommandSpans marks ctx so the commands sent with it are not traced, e.g.
+// the getMore polls of a change stream, which would flood traces and always be slow
+func withoutCommandSpans(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCommandSpansKey{}, true)
+}
+
+// operationSpan is the span of a Collection method. The first command the method
+// sends is recorded on it instead of on a child span, so wrapped calls are not
+// traced twice.
+type operationSpan struct {
+	span    trace.Span
+	claimed atomic.Bool
+}
+
+// commandSpan is a span tracked between the started and finished events of a command
+type commandSpan struct {
+	span trace.Span
+	// owned is true when the monitor started the span and must end it
+	owned           bool
+	operation       string
+	database        string
+	statement       Statement
+	recordStatement bool
+	// command is only kept when slow operations are explained
+	command bson.Raw
+}
+
+// commandTracer traces every command sent by the driver. Commands sent by a
+// Collection method are recorded on its span, any other command (getMore,
+// killCursors, raw driver calls such as cursor.All) gets its own child span of the
+// span in the command context.
+type commandTracer struct {
+	tracer             trace.Tracer
+	maxStatementLength int
+	disabledStatements map[string]bool
+	slow               *slowOperations
+	// spans holds the in-flight commands by RequestID
+	spans sync.Map
+}
+
+// newCommandTracer returns the tracer of the commands of a client
+func newCommandTracer(tracer trace.Tracer, meter metric.Meter, cfg APMConfig) (*commandTracer, error) {
+	slow, err := newSlowOperations(tracer, meter, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &commandTracer{
+		tracer:             tracer,
+		maxStatementLength: cfg.MaxStatementLength,
+		disabledStatements: map[string]bool{},
+		slow:               slow,
+	}
+	for _, name := range cfg.DisableStatementCollections {
+		t.disabledStatements[name] = true
+	}
+	return t, nil
+}
+
+// monitor returns the driver command monitor
+func (t *commandTracer) monitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started:   t.started,
+		Succeeded: t.succeeded,
+		Failed:    t.failed,
+	}
+}
+
+// started starts or claims the span of a command
+func (t *commandTracer) started(ctx context.Context, evt *event.CommandStartedEvent) {
+	if ctx.Value(noCommandSpansKey{}) != nil {
+		return
+	}
+
+	statement := SanitizeCommand(evt.Command, t.maxStatementLength)
+	attrs := []attribute.KeyValue{
+		attribute.String("db.connection_id", evt.ConnectionID),
+		attribute.Int64("db.mongodb.request_id", evt.RequestID),
+		attribute.String("db.query.shape_hash", statement.ShapeHash),
+	}
+	cs := commandSpan{
+		operation:       evt.CommandName,
+		database:        evt.DatabaseName,
+		statement:       statement,
+		recordStatement: !t.disabledStatements[statement.Collection],
+	}
+	if cs.recordStatement {
+		attrs = append(attrs, attribute.String("db.statement", statement.Text))
+	}
+	if t.slow != nil && t.slow.explains != nil && explainableCommands[evt.CommandName] {
+		// The event only owns the command until the callback returns
+		cs.command = append(bson.Raw(nil), evt.Command...)
+	}
+
+	// Spans are compared by SpanContext, the spans of some providers, such as the
+	// global one before it is set, are not comparable
+	if op, ok := ctx.Value(operationSpanKey{}).(*operationSpan); ok && op.span.SpanContext().IsValid() &&
+		op.span.SpanContext().Equal(trace.SpanContextFromContext(ctx)) && op.claimed.CompareAndSwap(false, true) {
+		// db.operation of the wrapper span stays the method name, e.g. FindOne
+		op.span.SetAttributes(attrs...)
+		op.span.SetAttributes(attribute.String("db.command", evt.CommandName))
+		cs.span = op.span
+		t.spans.Store(evt.RequestID, cs)
+		return
+	}
+
+	attrs = append(attrs,
+		attribute.String("db.system", "mongodb"),
+		attribute.String("db.name", evt.DatabaseName),
+		attribute.String("db.operation", evt.CommandName),
+	)
+	if statement.Collection != "" {
+		attrs = append(attrs, attribute.String("db.collection", statement.Collection))
+	}
+	_, span := t.tracer.Start(ctx, fmt.Sprintf("MongoDB.%s", evt.CommandName),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	cs.span = span
+	cs.owned = true
+	t.spans.Store(evt.RequestID, cs)
+}
+
+// succeeded ends the span of a successful command
+func (t *commandTracer) succeeded(ctx context.Context, evt *event.CommandSucceededEvent) {
+	value, ok := t.spans.LoadAndDelete(evt.RequestID)
+	if !ok {
+		return
+	}
+	cs := value.(commandSpan)
+	cs.span.SetAttributes(attribute.Int64("db.duration_ms", evt.Duration.Milliseconds()))
+	t.slow.record(trace.ContextWithSpan(ctx, cs.span), cs, evt.Duration, true)
+	if cs.owned {
+		cs.span.SetStatus(codes.Ok, "")
+		cs.span.End()
+	}
+}
+
+// failed ends the span of a failed command
+func (t *commandTracer) failed(ctx context.Context, evt *event.CommandFailedEvent) {
+	value, ok := t.spans.LoadAndDelete(evt.RequestID)
+	if !ok {
+		return
+	}
+	cs := value.(commandSpan)
+	cs.span.SetAttributes(attribute.Int64("db.duration_ms", evt.Duration.Milliseconds()))
+	t.slow.record(trace.ContextWithSpan(ctx, cs.span), cs, evt.Duration, false)
+	if !cs.owned {
+		// The wrapper reports the error it returns through handleError
+		return
+	}
+	cs.span.SetStatus(codes.Error, evt.Failure)
+	cs.span.RecordError(errors.New("mongodb command failed: " + evt.Failure))
+	cs.span.End()
+}
@@ -0,0 +1,89 @@
+package mongootel_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Doraverse-Workspace/open-observe/mongootel"
+	"github.com/Doraverse-Workspace/open-observe/oteltest"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestCommandMonitorClaimsOperationSpan(t *testing.T) {
+	r := oteltest.NewLocal(t)
+	server := newFakeServer(t)
+	server.Handle("find", func(bson.Raw) bson.D {
+		return cursorReply("firstBatch", 0, bson.D{{Key: "_id", Value: 1}, {Key: "email", Value: "ada@example.com"}})
+	})
+	client := newTestClient(t, server, mongootel.WithTracerProvider(r.TracerProvider))
+	r.Reset()
+
+	var user bson.M
+	if err := client.Collection("users").FindOne(context.Background(), bson.M{"email": "ada@example.com"}).Decode(&user); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := r.Spans()
+	findOne := spans.Find("MongoDB.FindOne").
+		HasKind(trace.SpanKindClient).
+		HasAttribute("db.operation", "FindOne").
+		HasAttribute("db.command", "find").
+		HasAttribute("db.collection", "users").
+		HasAttributeKey("db.statement").
+		HasAttributeKey("db.query.shape_hash").
+		HasStatus(codes.Ok)
+	if children := spans.ChildrenOf(findOne.Span()); children.Len() != 0 {
+		t.Errorf("MongoDB.FindOne has children %v, want the find command recorded on it", children.Names())
+	}
+	if statement := attributeString(findOne.Span().Attributes(), "db.statement"); strings.Contains(statement, "ada@example.com") {
+		t.Errorf("db.statement = %s, want the filter value replaced", statement)
+	}
+}
+
+func TestCommandMonitorChildSpans(t *testing.T) {
+	r := oteltest.NewLocal(t)
+	server := newFakeServer(t)
+	server.Handle("count", func(bson.Raw) bson.D {
+		return bson.D{{Key: "ok", Value: 0}, {Key: "code", Value: 2}, {Key: "codeName", Value: "BadValue"}, {Key: "errmsg", Value: "bad count"}}
+	})
+	client := newTestClient(t, server, mongootel.WithTracerProvider(r.TracerProvider))
+	r.Reset()
+
+	ctx, parent := r.Tracer("test").Start(context.Background(), "parent")
+	db := client.Driver().Database("test")
+	if err := db.RunCommand(ctx, bson.D{{Key: "ping", Value: 1}}).Err(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.RunCommand(ctx, bson.D{{Key: "count", Value: "users"}}).Err(); err == nil {
+		t.Fatal("count succeeded, want the scripted error")
+	}
+	parent.End()
+
+	spans := r.Spans()
+	spans.Find("MongoDB.ping").
+		HasParent("parent").
+		HasKind(trace.SpanKindClient).
+		HasAttribute("db.system", "mongodb").
+		HasAttribute("db.name", "test").
+		HasAttribute("db.operation", "ping").
+		HasStatus(codes.Ok)
+	spans.Find("MongoDB.count").
+		HasParent("parent").
+		HasAttribute("db.collection", "users").
+		HasStatus(codes.Error).
+		HasEvent("exception")
+}
+
+// attributeString returns the string value of key in attrs
+func attributeString(attrs []attribute.KeyValue, key string) string {
+	for _, kv := range attrs {
+		if string(kv.Key) == key {
+			return kv.Value.Emit()
+		}
+	}
+	return ""
+}
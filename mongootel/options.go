@@ -0,0 +1,46 @@
+package mongootel
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the spans and metrics of the package
+const ScopeName = "mongodb"
+
+// Option configures NewClient
+type Option func(*clientOptions)
+
+type clientOptions struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+}
+
+// WithTracerProvider sets the tracer provider of the client spans, the global
+// provider is used by default
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(o *clientOptions) {
+		o.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider sets the meter provider of the client metrics, the global
+// provider is used by default
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(o *clientOptions) {
+		o.meterProvider = provider
+	}
+}
+
+// newClientOptions applies opts over the global providers
+func newClientOptions(opts []Option) clientOptions {
+	o := clientOptions{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
@@ -0,0 +1,160 @@
+package mongootel
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Connection states of db.client.connection.count
+const (
+	connectionStateIdle = "idle"
+	connectionStateUsed = "used"
+	// connectionStatePending is a connection created but not ready yet, it is not counted
+	connectionStatePending = "pending"
+)
+
+// poolConnection identifies a connection of the pool of a server
+type poolConnection struct {
+	address string
+	id      uint64
+}
+
+// poolMetrics turns connection pool events into metrics and debug logs
+type poolMetrics struct {
+	count    metric.Int64UpDownCounter
+	created  metric.Int64Counter
+	closed   metric.Int64Counter
+	waitTime metric.Float64Histogram
+	failures metric.Int64Counter
+
+	mu sync.Mutex
+	// states holds the state of every open connection, so closing a connection
+	// decrements the right count
+	states map[poolConnection]string
+}
+
+// newPoolMonitor returns the driver pool monitor of a client
+func newPoolMonitor(meter metric.Meter) (*event.PoolMonitor, error) {
+	m := &poolMetrics{states: map[poolConnection]string{}}
+
+	var err error
+	if m.count, err = meter.Int64UpDownCounter(
+		"db.client.connection.count",
+		metric.WithDescription("The number of connections that are currently in state described by the state attribute."),
+		metric.WithUnit("{connection}"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create MongoDB connection count: %w", err)
+	}
+	if m.created, err = meter.Int64Counter(
+		"db.client.connection.created",
+		metric.WithDescription("The number of connections created."),
+		metric.WithUnit("{connection}"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create MongoDB connection created counter: %w", err)
+	}
+	if m.closed, err = meter.Int64Counter(
+		"db.client.connection.closed",
+		metric.WithDescription("The number of connections closed, by reason."),
+		metric.WithUnit("{connection}"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create MongoDB connection closed counter: %w", err)
+	}
+	if m.waitTime, err = meter.Float64Histogram(
+		"db.client.connection.wait_time",
+		metric.WithDescription("The time it took to obtain a connection from the pool."),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create MongoDB connection wait time histogram: %w", err)
+	}
+	if m.failures, err = meter.Int64Counter(
+		"db.client.connection.checkout_failures",
+		metric.WithDescription("The number of failed connection check-outs, by reason."),
+		metric.WithUnit("{failure}"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to create MongoDB connection check-out failures counter: %w", err)
+	}
+
+	return &event.PoolMonitor{Event: m.event}, nil
+}
+
+// event records a pool event and logs it at debug level
+func (m *poolMetrics) event(evt *event.PoolEvent) {
+	ctx := context.Background()
+	server := attribute.String("server.address", evt.Address)
+	conn := poolConnection{address: evt.Address, id: evt.ConnectionID}
+
+	switch evt.Type {
+	case event.ConnectionCreated:
+		m.created.Add(ctx, 1, metric.WithAttributes(server))
+		m.setState(ctx, conn, connectionStatePending)
+	case event.ConnectionReady:
+		m.setState(ctx, conn, connectionStateIdle)
+	case event.GetSucceeded:
+		m.waitTime.Record(ctx, evt.Duration.Seconds(), metric.WithAttributes(server))
+		m.setState(ctx, conn, connectionStateUsed)
+	case event.ConnectionReturned:
+		m.setState(ctx, conn, connectionStateIdle)
+	case event.GetFailed:
+		m.waitTime.Record(ctx, evt.Duration.Seconds(), metric.WithAttributes(server))
+		m.failures.Add(ctx, 1, metric.WithAttributes(server, attribute.String("reason", evt.Reason)))
+	case event.ConnectionClosed:
+		m.closed.Add(ctx, 1, metric.WithAttributes(server, attribute.String("reason", evt.Reason)))
+		m.setState(ctx, conn, "")
+	}
+
+	if !slog.Default().Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	attrs := []any{
+		slog.String("type", evt.Type),
+		slog.String("address", evt.Address),
+	}
+	if evt.ConnectionID != 0 {
+		attrs = append(attrs, slog.Uint64("connection_id", evt.ConnectionID))
+	}
+	if evt.Reason != "" {
+		attrs = append(attrs, slog.String("reason", evt.Reason))
+	}
+	if evt.Duration > 0 {
+		attrs = append(attrs, slog.Duration("duration", evt.Duration))
+	}
+	if evt.Error != nil {
+		attrs = append(attrs, slog.String("error", evt.Error.Error()))
+	}
+	slog.DebugContext(ctx, "MongoDB pool event", attrs...)
+}
+
+// setState moves a connection to state, an empty state removes it
+func (m *poolMetrics) setState(ctx context.Context, conn poolConnection, state string) {
+	m.mu.Lock()
+	previous := m.states[conn]
+	if state == "" {
+		delete(m.states, conn)
+	} else {
+		m.states[conn] = state
+	}
+	m.mu.Unlock()
+
+	if previous == state {
+		return
+	}
+	if previous == connectionStateIdle || previous == connectionStateUsed {
+		m.count.Add(ctx, -1, metric.WithAttributes(
+			attribute.String("server.address", conn.address),
+			attribute.String("db.client.connection.state", previous),
+		))
+	}
+	if state == connectionStateIdle || state == connectionStateUsed {
+		m.count.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("server.address", conn.address),
+			attribute.String("db.client.connection.state", state),
+		))
+	}
+}
@@ -0,0 +1,77 @@
+package mongootel_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Doraverse-Workspace/open-observe/mongootel"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// collect returns the metrics of reader by name
+func collect(t *testing.T, reader sdkmetric.Reader) map[string]metricdata.Aggregation {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	metrics := map[string]metricdata.Aggregation{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+	return metrics
+}
+
+// sum adds the int64 data points of a sum that have the attribute, any when key is empty
+func sum(data metricdata.Aggregation, key, value string) int64 {
+	s, _ := data.(metricdata.Sum[int64])
+	var total int64
+	for _, dp := range s.DataPoints {
+		if v, ok := dp.Attributes.Value(attribute.Key(key)); key == "" || ok && v.AsString() == value {
+			total += dp.Value
+		}
+	}
+	return total
+}
+
+func TestPoolMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	server := newFakeServer(t)
+	client := newTestClient(t, server, mongootel.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))))
+
+	if err := client.Collection("users").FindOne(context.Background(), bson.M{}).Err(); err == nil {
+		t.Fatal("FindOne() found a document, want mongo.ErrNoDocuments")
+	}
+
+	metrics := collect(t, reader)
+	if got := sum(metrics["db.client.connection.created"], "", ""); got < 1 {
+		t.Errorf("db.client.connection.created = %d, want at least 1", got)
+	}
+	if got := sum(metrics["db.client.connection.count"], "db.client.connection.state", "idle"); got != sum(metrics["db.client.connection.created"], "", "") {
+		t.Errorf("idle db.client.connection.count = %d, want every created connection", got)
+	}
+	if got := sum(metrics["db.client.connection.count"], "db.client.connection.state", "used"); got != 0 {
+		t.Errorf("used db.client.connection.count = %d, want 0", got)
+	}
+	waitTime, _ := metrics["db.client.connection.wait_time"].(metricdata.Histogram[float64])
+	if len(waitTime.DataPoints) == 0 || waitTime.DataPoints[0].Count == 0 {
+		t.Error("db.client.connection.wait_time has no measurement")
+	}
+
+	if err := client.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	metrics = collect(t, reader)
+	if got := sum(metrics["db.client.connection.count"], "", ""); got != 0 {
+		t.Errorf("db.client.connection.count = %d after Close, want 0", got)
+	}
+	if got := sum(metrics["db.client.connection.closed"], "reason", "poolClosed"); got != sum(metrics["db.client.connection.created"], "", "") {
+		t.Errorf("db.client.connection.closed{reason=poolClosed} = %d, want every created connection", got)
+	}
+}
@@ -0,0 +1,369 @@
+package mongootel_test
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Doraverse-Workspace/open-observe/mongootel"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Wire protocol op codes the fake server speaks
+const (
+	opReply = 1
+	opQuery = 2004
+	opMsg   = 2013
+)
+
+// msgMoreToCome is the OP_MSG flag of a message that gets no reply
+const msgMoreToCome = 1 << 1
+
+// handler answers a command, the reply gets ok: 1 unless it sets ok
+type handler func(command bson.Raw) bson.D
+
+// fakeServer is a MongoDB stand-in speaking enough of the wire protocol for the
+// driver: it answers the handshake and heartbeats as the primary of a replica
+// set, so sessions and transactions are allowed, and every other command with
+// the handler registered for its name, or ok: 1.
+type fakeServer struct {
+	tb       testing.TB
+	listener net.Listener
+	requests atomic.Int32
+
+	mu       sync.Mutex
+	handlers map[string]handler
+	commands []bson.Raw
+	conns    map[net.Conn]bool
+}
+
+// newFakeServer listens on a local port until the test ends
+func newFakeServer(tb testing.TB) *fakeServer {
+	tb.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatalf("failed to listen: %v", err)
+	}
+	s := &fakeServer{
+		tb:       tb,
+		listener: listener,
+		handlers: map[string]handler{},
+		conns:    map[net.Conn]bool{},
+	}
+	go s.serve()
+	tb.Cleanup(s.close)
+	return s
+}
+
+// URI is the connection string of the server
+func (s *fakeServer) URI() string {
+	return "mongodb://" + s.listener.Addr().String() + "/?directConnection=true"
+}
+
+// Handle answers the commands named name with h
+func (s *fakeServer) Handle(name string, h handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[name] = h
+}
+
+// HandleSequence answers the commands named name with the replies in order, the
+// last one is repeated
+func (s *fakeServer) HandleSequence(name string, replies ...bson.D) {
+	var calls atomic.Int32
+	s.Handle(name, func(bson.Raw) bson.D {
+		n := int(calls.Add(1)) - 1
+		return replies[min(n, len(replies)-1)]
+	})
+}
+
+// Commands returns the commands named name received so far
+func (s *fakeServer) Commands(name string) []bson.Raw {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var commands []bson.Raw
+	for _, command := range s.commands {
+		if commandName(command) == name {
+			commands = append(commands, command)
+		}
+	}
+	return commands
+}
+
+func (s *fakeServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[conn] = true
+		s.mu.Unlock()
+		go s.serveConn(conn)
+	}
+}
+
+func (s *fakeServer) close() {
+	_ = s.listener.Close()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+}
+
+// serveConn reads messages until the connection is closed
+func (s *fakeServer) serveConn(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		_ = conn.Close()
+	}()
+
+	for {
+		header := make([]byte, 16)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		length := binary.LittleEndian.Uint32(header[0:])
+		requestID := binary.LittleEndian.Uint32(header[4:])
+		opCode := binary.LittleEndian.Uint32(header[12:])
+		body := make([]byte, length-16)
+		if _, err := io.ReadFull(conn, body); err != nil {
+			return
+		}
+
+		var message []byte
+		switch opCode {
+		case opQuery:
+			command, err := parseQuery(body)
+			if err != nil {
+				s.tb.Errorf("fake server: %v", err)
+				return
+			}
+			message = s.message(opReply, requestID, s.reply(command))
+		case opMsg:
+			flags, command, err := parseMsg(body)
+			if err != nil {
+				s.tb.Errorf("fake server: %v", err)
+				return
+			}
+			reply := s.reply(command)
+			if flags&msgMoreToCome != 0 {
+				continue
+			}
+			message = s.message(opMsg, requestID, reply)
+		default:
+			s.tb.Errorf("fake server: unsupported op code %d", opCode)
+			return
+		}
+		if _, err := conn.Write(message); err != nil {
+			return
+		}
+	}
+}
+
+// reply records command and runs its handler
+func (s *fakeServer) reply(command bson.Raw) bson.Raw {
+	name := commandName(command)
+	var reply bson.D
+	switch name {
+	case "hello", "isMaster", "ismaster":
+		reply = s.hello()
+	default:
+		s.mu.Lock()
+		s.commands = append(s.commands, command)
+		h := s.handlers[name]
+		s.mu.Unlock()
+		if h != nil {
+			reply = h(command)
+		}
+	}
+
+	hasOK := false
+	for _, e := range reply {
+		hasOK = hasOK || e.Key == "ok"
+	}
+	if !hasOK {
+		reply = append(reply, bson.E{Key: "ok", Value: 1})
+	}
+	raw, err := bson.Marshal(reply)
+	if err != nil {
+		s.tb.Errorf("fake server: failed to marshal the reply to %s: %v", name, err)
+	}
+	return raw
+}
+
+// hello describes the server as the writable primary of replica set rs0. It has
+// no topologyVersion so the driver polls instead of streaming heartbeats.
+func (s *fakeServer) hello() bson.D {
+	addr := s.listener.Addr().String()
+	return bson.D{
+		{Key: "helloOk", Value: true},
+		{Key: "isWritablePrimary", Value: true},
+		{Key: "ismaster", Value: true},
+		{Key: "setName", Value: "rs0"},
+		{Key: "setVersion", Value: 1},
+		{Key: "hosts", Value: bson.A{addr}},
+		{Key: "primary", Value: addr},
+		{Key: "me", Value: addr},
+		{Key: "maxBsonObjectSize", Value: 16 * 1024 * 1024},
+		{Key: "maxMessageSizeBytes", Value: 48000000},
+		{Key: "maxWriteBatchSize", Value: 100000},
+		{Key: "localTime", Value: time.Now()},
+		{Key: "logicalSessionTimeoutMinutes", Value: 30},
+		{Key: "connectionId", Value: 1},
+		{Key: "minWireVersion", Value: 0},
+		{Key: "maxWireVersion", Value: 21},
+	}
+}
+
+// message frames reply as an OP_REPLY or OP_MSG answering requestID
+func (s *fakeServer) message(opCode, requestID uint32, reply bson.Raw) []byte {
+	var body []byte
+	if opCode == opReply {
+		body = binary.LittleEndian.AppendUint32(body, 0) // responseFlags
+		body = binary.LittleEndian.AppendUint64(body, 0) // cursorID
+		body = binary.LittleEndian.AppendUint32(body, 0) // startingFrom
+		body = binary.LittleEndian.AppendUint32(body, 1) // numberReturned
+	} else {
+		body = binary.LittleEndian.AppendUint32(body, 0) // flagBits
+		body = append(body, 0)                           // kind 0 section
+	}
+	body = append(body, reply...)
+
+	message := binary.LittleEndian.AppendUint32(nil, uint32(16+len(body)))
+	message = binary.LittleEndian.AppendUint32(message, uint32(s.requests.Add(1)))
+	message = binary.LittleEndian.AppendUint32(message, requestID)
+	message = binary.LittleEndian.AppendUint32(message, opCode)
+	return append(message, body...)
+}
+
+// parseQuery returns the command of an OP_QUERY, the legacy handshake
+func parseQuery(body []byte) (bson.Raw, error) {
+	// flags, then the full collection name
+	i := 4
+	for i < len(body) && body[i] != 0 {
+		i++
+	}
+	// numberToSkip and numberToReturn
+	i += 1 + 8
+	return readDocument(body[i:])
+}
+
+// parseMsg returns the flags and the command of an OP_MSG, with the document
+// sequences, such as the documents of insert, added as arrays
+func parseMsg(body []byte) (uint32, bson.Raw, error) {
+	flags := binary.LittleEndian.Uint32(body)
+	if flags&1 != 0 {
+		// checksumPresent
+		body = body[:len(body)-4]
+	}
+
+	var command bson.D
+	var sequences bson.D
+	for i := 4; i < len(body); {
+		kind := body[i]
+		i++
+		switch kind {
+		case 0:
+			doc, err := readDocument(body[i:])
+			if err != nil {
+				return 0, nil, err
+			}
+			if err := bson.Unmarshal(doc, &command); err != nil {
+				return 0, nil, err
+			}
+			i += len(doc)
+		case 1:
+			size := int(binary.LittleEndian.Uint32(body[i:]))
+			end := i + size
+			j := i + 4
+			start := j
+			for body[j] != 0 {
+				j++
+			}
+			identifier := string(body[start:j])
+			j++
+			var docs bson.A
+			for j < end {
+				doc, err := readDocument(body[j:end])
+				if err != nil {
+					return 0, nil, err
+				}
+				docs = append(docs, doc)
+				j += len(doc)
+			}
+			sequences = append(sequences, bson.E{Key: identifier, Value: docs})
+			i = end
+		default:
+			return 0, nil, errors.New("unsupported OP_MSG section kind")
+		}
+	}
+
+	raw, err := bson.Marshal(append(command, sequences...))
+	return flags, raw, err
+}
+
+// readDocument returns the BSON document at the start of b
+func readDocument(b []byte) (bson.Raw, error) {
+	if len(b) < 5 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	length := int(binary.LittleEndian.Uint32(b))
+	if length > len(b) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return bson.Raw(b[:length]), nil
+}
+
+// commandName is the first key of a command
+func commandName(command bson.Raw) string {
+	elements, err := command.Elements()
+	if err != nil || len(elements) == 0 {
+		return ""
+	}
+	return elements[0].Key()
+}
+
+// cursorReply is the reply of a command opening or continuing a cursor
+func cursorReply(batch string, id int64, docs ...bson.D) bson.D {
+	documents := bson.A{}
+	for _, doc := range docs {
+		documents = append(documents, doc)
+	}
+	return bson.D{{Key: "cursor", Value: bson.D{
+		{Key: batch, Value: documents},
+		{Key: "id", Value: id},
+		{Key: "ns", Value: "test.users"},
+	}}}
+}
+
+// newTestClient connects a client to the server, without slow operation tracking
+func newTestClient(t *testing.T, server *fakeServer, opts ...mongootel.Option) *mongootel.Client {
+	t.Helper()
+
+	cfg := mongootel.DefaultConfig()
+	cfg.URI = server.URI()
+	cfg.MinPoolSize = 0
+	cfg.Timeout = 5 * time.Second
+	cfg.APMConfig.SlowOperationThreshold = 0
+
+	client, err := mongootel.NewClient(context.Background(), cfg, opts...)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = client.Close(context.Background())
+	})
+	return client
+}
@@ -0,0 +1,273 @@
+package mongootel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
+)
+
+// defaultMaxExplainsPerMinute is used when APMConfig.MaxExplainsPerMinute is not set
+const defaultMaxExplainsPerMinute = 6
+
+// maxConcurrentExplains bounds the explain commands running in the background, so
+// that they never hold more than a few connections of the pool
+const maxConcurrentExplains = 2
+
+// explainableCommands are the commands the explain command supports
+var explainableCommands = map[string]bool{
+	"find":          true,
+	"aggregate":     true,
+	"count":         true,
+	"distinct":      true,
+	"update":        true,
+	"delete":        true,
+	"findAndModify": true,
+}
+
+// slowOperations flags commands slower than APMConfig.SlowOperationThreshold
+type slowOperations struct {
+	threshold time.Duration
+	counter   metric.Int64Counter
+	tracer    trace.Tracer
+	// explains is nil when APMConfig.ExplainSlowOperations is not set
+	explains *rate.Limiter
+	// running holds a token per explain running in the background
+	running chan struct{}
+	// client runs the explain commands, it is set once connected
+	client atomic.Pointer[mongo.Client]
+
+	// ctx is canceled by close, which waits for the explains in wg
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	closed bool
+}
+
+// newSlowOperations returns nil when the threshold is not set
+func newSlowOperations(tracer trace.Tracer, meter metric.Meter, cfg APMConfig) (*slowOperations, error) {
+	if cfg.SlowOperationThreshold <= 0 {
+		return nil, nil
+	}
+
+	counter, err := meter.Int64Counter(
+		"db.client.operation.slow",
+		metric.WithDescription("The number of MongoDB operations slower than the slow operation threshold."),
+		metric.WithUnit("{operation}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MongoDB slow operation counter: %w", err)
+	}
+
+	s := &slowOperations{threshold: cfg.SlowOperationThreshold, counter: counter, tracer: tracer}
+	if cfg.ExplainSlowOperations {
+		perMinute := cfg.MaxExplainsPerMinute
+		if perMinute <= 0 {
+			perMinute = defaultMaxExplainsPerMinute
+		}
+		s.explains = rate.NewLimiter(rate.Every(time.Minute/time.Duration(perMinute)), 1)
+		s.running = make(chan struct{}, maxConcurrentExplains)
+		s.ctx, s.cancel = context.WithCancel(context.Background())
+	}
+	return s, nil
+}
+
+// record flags the command on its span, logs a warning and counts it when it
+// took longer than the threshold
+func (s *slowOperations) record(ctx context.Context, cs commandSpan, duration time.Duration, succeeded bool) {
+	if s == nil || duration < s.threshold {
+		return
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("db.operation", cs.operation),
+		attribute.Float64("db.duration_ms", float64(duration.Microseconds())/1000),
+		attribute.Float64("db.slow_threshold_ms", float64(s.threshold.Microseconds())/1000),
+		attribute.String("db.query.shape_hash", cs.statement.ShapeHash),
+	}
+	if cs.recordStatement {
+		attrs = append(attrs, attribute.String("db.statement", cs.statement.Text))
+	}
+	cs.span.SetAttributes(attribute.Bool("db.slow", true))
+	cs.span.AddEvent("db.slow_operation", trace.WithAttributes(attrs...))
+
+	s.counter.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("db.system", "mongodb"),
+		attribute.String("db.collection", cs.statement.Collection),
+		attribute.String("db.operation", cs.operation),
+	))
+
+	logAttrs := []any{
+		slog.String("db.collection", cs.statement.Collection),
+		slog.String("db.operation", cs.operation),
+		slog.Duration("duration", duration),
+		slog.Duration("threshold", s.threshold),
+		slog.String("db.query.shape_hash", cs.statement.ShapeHash),
+	}
+	if cs.recordStatement {
+		logAttrs = append(logAttrs, slog.String("db.statement", cs.statement.Text))
+	}
+	slog.WarnContext(ctx, "Slow MongoDB operation", logAttrs...)
+
+	if succeeded {
+		s.explainInBackground(cs)
+	}
+}
+
+// explainInBackground explains the slow command in a goroutine. The plan is
+// recorded on a MongoDB.Explain span linked to the command span, which has ended
+// by then, and logged with the trace of the command. It runs from the command
+// monitor callback, where a synchronous command could wait for a connection the
+// pool cannot give, so the explain is skipped when explains are disabled, rate
+// limited, already maxConcurrentExplains are running or the client is closed.
+func (s *slowOperations) explainInBackground(cs commandSpan) {
+	client := s.client.Load()
+	if s.explains == nil || client == nil || cs.command == nil || !explainableCommands[cs.operation] {
+		return
+	}
+	select {
+	case s.running <- struct{}{}:
+	default:
+		return
+	}
+	if !s.explains.Allow() {
+		<-s.running
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		<-s.running
+		return
+	}
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+		defer func() { <-s.running }()
+
+		// A fresh context keeps the explain out of the session and transaction of the command
+		ctx, span := s.tracer.Start(s.ctx, "MongoDB.Explain",
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithLinks(trace.Link{SpanContext: cs.span.SpanContext()}),
+			trace.WithAttributes(
+				attribute.String("db.system", "mongodb"),
+				attribute.String("db.name", cs.database),
+				attribute.String("db.collection", cs.statement.Collection),
+				attribute.String("db.operation", "explain"),
+				attribute.String("db.mongodb.explained_operation", cs.operation),
+				attribute.String("db.query.shape_hash", cs.statement.ShapeHash),
+			),
+		)
+		defer span.End()
+
+		plan, err := s.explain(ctx, client, cs)
+		handleError(span, err)
+		if err != nil {
+			return
+		}
+		span.SetAttributes(attribute.String("db.mongodb.explain", plan))
+
+		// The log record carries the trace and span IDs of the command
+		logCtx := trace.ContextWithSpanContext(context.Background(), cs.span.SpanContext())
+		slog.InfoContext(logCtx, "Explain plan of slow MongoDB operation",
+			slog.String("db.collection", cs.statement.Collection),
+			slog.String("db.operation", cs.operation),
+			slog.String("db.query.shape_hash", cs.statement.ShapeHash),
+			slog.String("db.mongodb.explain", plan),
+		)
+	}()
+}
+
+// close cancels the running explains and waits for them to return
+func (s *slowOperations) close() {
+	if s == nil || s.cancel == nil {
+		return
+	}
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+
+	s.cancel()
+	s.wg.Wait()
+}
+
+// explain runs the explain command for the slow command and summarizes the
+// winning plan, e.g. "FETCH <- IXSCAN(email_1)"
+func (s *slowOperations) explain(ctx context.Context, client *mongo.Client, cs commandSpan) (string, error) {
+	elements, err := cs.command.Elements()
+	if err != nil {
+		return "", err
+	}
+	command := bson.D{}
+	for _, element := range elements {
+		if driverFields[element.Key()] {
+			continue
+		}
+		command = append(command, bson.E{Key: element.Key(), Value: element.Value()})
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.threshold)
+	defer cancel()
+	result, err := client.Database(cs.database).RunCommand(ctx, bson.D{
+		{Key: "explain", Value: command},
+		{Key: "verbosity", Value: "queryPlanner"},
+	}).Raw()
+	if err != nil {
+		return "", err
+	}
+
+	plan, err := result.LookupErr("queryPlanner", "winningPlan")
+	if err != nil || plan.Type != bsontype.EmbeddedDocument {
+		return "", errors.New("explain returned no winning plan")
+	}
+	// Slot based execution (MongoDB 6.0+) nests the plan in queryPlan
+	if queryPlan, err := plan.Document().LookupErr("queryPlan"); err == nil {
+		plan = queryPlan
+	}
+	return planSummary(plan), nil
+}
+
+// planSummary lists the stages of a plan from the root to the leaf with the index
+// each stage uses. Filters are left out so no literal value is recorded.
+func planSummary(plan bson.RawValue) string {
+	var stages []string
+	for plan.Type == bsontype.EmbeddedDocument {
+		doc := plan.Document()
+		stage, _ := doc.Lookup("stage").StringValueOK()
+		if index, ok := doc.Lookup("indexName").StringValueOK(); ok {
+			stage = fmt.Sprintf("%s(%s)", stage, index)
+		}
+		stages = append(stages, stage)
+
+		next, err := doc.LookupErr("inputStage")
+		if err != nil {
+			// Stages such as OR have several inputs, only the first one is followed
+			inputs, err := doc.LookupErr("inputStages")
+			if err != nil || inputs.Type != bsontype.Array {
+				break
+			}
+			values, err := inputs.Array().Values()
+			if err != nil || len(values) == 0 {
+				break
+			}
+			next = values[0]
+		}
+		plan = next
+	}
+	return strings.Join(stages, " <- ")
+}
@@ -0,0 +1,118 @@
+package mongootel_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Doraverse-Workspace/open-observe/mongootel"
+	"github.com/Doraverse-Workspace/open-observe/oteltest"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newExplainingClient connects a client to the server that explains the commands
+// slower than threshold
+func newExplainingClient(t *testing.T, server *fakeServer, threshold time.Duration, opts ...mongootel.Option) *mongootel.Client {
+	t.Helper()
+
+	cfg := mongootel.DefaultConfig()
+	cfg.URI = server.URI()
+	cfg.MinPoolSize = 0
+	cfg.Timeout = 5 * time.Second
+	cfg.APMConfig.SlowOperationThreshold = threshold
+	cfg.APMConfig.ExplainSlowOperations = true
+
+	client, err := mongootel.NewClient(context.Background(), cfg, opts...)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = client.Close(context.Background())
+	})
+	return client
+}
+
+// slowFind answers find commands after delay
+func slowFind(delay time.Duration) handler {
+	return func(bson.Raw) bson.D {
+		time.Sleep(delay)
+		return cursorReply("firstBatch", 0, bson.D{{Key: "_id", Value: 1}})
+	}
+}
+
+func TestSlowOperationExplainSpan(t *testing.T) {
+	r := oteltest.NewLocal(t)
+	server := newFakeServer(t)
+	server.Handle("find", slowFind(60*time.Millisecond))
+	server.Handle("explain", func(bson.Raw) bson.D {
+		return bson.D{{Key: "queryPlanner", Value: bson.D{{Key: "winningPlan", Value: bson.D{
+			{Key: "stage", Value: "FETCH"},
+			{Key: "inputStage", Value: bson.D{{Key: "stage", Value: "IXSCAN"}, {Key: "indexName", Value: "email_1"}}},
+		}}}}}
+	})
+	client := newExplainingClient(t, server, 40*time.Millisecond, mongootel.WithTracerProvider(r.TracerProvider))
+	r.Reset()
+
+	var user bson.M
+	if err := client.Collection("users").FindOne(context.Background(), bson.M{"email": "ada@example.com"}).Decode(&user); err != nil {
+		t.Fatal(err)
+	}
+	// The explain runs in the background
+	for start := time.Now(); r.Spans().Named("MongoDB.Explain").Len() == 0; time.Sleep(5 * time.Millisecond) {
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("no MongoDB.Explain span")
+		}
+	}
+
+	spans := r.Spans()
+	findOne := spans.Find("MongoDB.FindOne").HasAttribute("db.slow", true).HasEvent("db.slow_operation")
+	explain := spans.Find("MongoDB.Explain").
+		IsRoot().
+		HasKind(trace.SpanKindClient).
+		HasAttribute("db.collection", "users").
+		HasAttribute("db.operation", "explain").
+		HasAttribute("db.mongodb.explained_operation", "find").
+		HasAttribute("db.mongodb.explain", "FETCH <- IXSCAN(email_1)").
+		HasChild("MongoDB.explain")
+	links := explain.Span().Links()
+	if len(links) != 1 || !links[0].SpanContext.Equal(findOne.Span().SpanContext()) {
+		t.Errorf("MongoDB.Explain links = %v, want the MongoDB.FindOne span", links)
+	}
+
+	commands := server.Commands("explain")
+	if len(commands) != 1 {
+		t.Fatalf("%d explain commands, want 1", len(commands))
+	}
+	if _, err := commands[0].LookupErr("explain", "lsid"); err == nil {
+		t.Error("the explained command kept the session of the find command")
+	}
+}
+
+func TestCloseCancelsExplains(t *testing.T) {
+	const threshold = 500 * time.Millisecond
+
+	r := oteltest.NewLocal(t)
+	server := newFakeServer(t)
+	server.Handle("find", slowFind(threshold+50*time.Millisecond))
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	server.Handle("explain", func(bson.Raw) bson.D {
+		<-release
+		return bson.D{}
+	})
+	client := newExplainingClient(t, server, threshold, mongootel.WithTracerProvider(r.TracerProvider))
+	r.Reset()
+
+	if err := client.Collection("users").FindOne(context.Background(), bson.M{}).Err(); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	_ = client.Close(context.Background())
+	if elapsed := time.Since(start); elapsed >= threshold/2 {
+		t.Errorf("Close took %v, want the running explain canceled", elapsed)
+	}
+
+	// The explain span has ended by the time Close returns
+	r.Find("MongoDB.Explain").LacksAttribute("db.mongodb.explain")
+}
@@ -0,0 +1,133 @@
+package mongootel
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// defaultMaxStatementLength is used when APMConfig.MaxStatementLength is not set
+const defaultMaxStatementLength = 2048
+
+// driverFields are added to every command by the driver, they are not part of the query
+var driverFields = map[string]bool{
+	"lsid":             true,
+	"$clusterTime":     true,
+	"$db":              true,
+	"txnNumber":        true,
+	"autocommit":       true,
+	"startTransaction": true,
+	"$readPreference":  true,
+	"readConcern":      true,
+	"writeConcern":     true,
+	"apiVersion":       true,
+	"apiStrict":        true,
+}
+
+// Statement is a sanitized MongoDB command
+// Text: the command with literal values replaced by "?", truncated to the maximum length
+// ShapeHash: a stable hash of the full sanitized command, equal for queries of the same shape
+// Collection: the collection the command runs on, empty when unknown
+type Statement struct {
+	Text       string
+	ShapeHash  string
+	Collection string
+}
+
+// SanitizeCommand replaces the literal values of a command with "?" while keeping
+// field names and operators. Arrays are reduced to the shape of their first element,
+// so $in lists and insert payloads of any size have the same shape. The collection
+// name, the first value of the command, is kept.
+func SanitizeCommand(command bson.Raw, maxLength int) Statement {
+	elements, err := command.Elements()
+	if err != nil || len(elements) == 0 {
+		return Statement{}
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('{')
+	written := 0
+	collection := ""
+	for i, element := range elements {
+		key := element.Key()
+		if driverFields[key] {
+			continue
+		}
+		if written > 0 {
+			sb.WriteString(", ")
+		}
+		written++
+		sb.WriteString(strconv.Quote(key))
+		sb.WriteString(": ")
+
+		value := element.Value()
+		switch {
+		case i == 0 && value.Type == bsontype.String:
+			// The command name's value is the collection, e.g. {"find": "users"}
+			collection = value.StringValue()
+			sb.WriteString(strconv.Quote(collection))
+		case key == "collection" && value.Type == bsontype.String:
+			// getMore names its collection in the collection field
+			collection = value.StringValue()
+			sb.WriteString(strconv.Quote(collection))
+		default:
+			writeSanitizedValue(&sb, value)
+		}
+	}
+	sb.WriteByte('}')
+
+	shape := sb.String()
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(shape))
+
+	if maxLength <= 0 {
+		maxLength = defaultMaxStatementLength
+	}
+	if len(shape) > maxLength {
+		shape = shape[:maxLength] + "..."
+	}
+
+	return Statement{
+		Text:       shape,
+		ShapeHash:  fmt.Sprintf("%016x", hash.Sum64()),
+		Collection: collection,
+	}
+}
+
+// writeSanitizedValue writes the shape of a value: documents keep their keys,
+// arrays keep their first element and every literal becomes "?"
+func writeSanitizedValue(sb *strings.Builder, value bson.RawValue) {
+	switch value.Type {
+	case bsontype.EmbeddedDocument:
+		elements, err := value.Document().Elements()
+		if err != nil {
+			sb.WriteString(`"?"`)
+			return
+		}
+		sb.WriteByte('{')
+		for i, element := range elements {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(strconv.Quote(element.Key()))
+			sb.WriteString(": ")
+			writeSanitizedValue(sb, element.Value())
+		}
+		sb.WriteByte('}')
+	case bsontype.Array:
+		values, err := value.Array().Values()
+		if err != nil || len(values) == 0 {
+			sb.WriteString("[]")
+			return
+		}
+		sb.WriteByte('[')
+		writeSanitizedValue(sb, values[0])
+		sb.WriteByte(']')
+	default:
+		sb.WriteString(`"?"`)
+	}
+}
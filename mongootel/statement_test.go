@@ -0,0 +1,126 @@
+package mongootel_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Doraverse-Workspace/open-observe/mongootel"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func mustMarshal(t *testing.T, command bson.D) bson.Raw {
+	t.Helper()
+	raw, err := bson.Marshal(command)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestSanitizeCommand(t *testing.T) {
+	tests := []struct {
+		name       string
+		command    bson.D
+		want       string
+		collection string
+	}{
+		{
+			name: "find",
+			command: bson.D{
+				{Key: "find", Value: "users"},
+				{Key: "filter", Value: bson.D{{Key: "email", Value: "ada@example.com"}, {Key: "age", Value: bson.D{{Key: "$gt", Value: 30}}}}},
+				{Key: "limit", Value: 1},
+			},
+			want:       `{"find": "users", "filter": {"email": "?", "age": {"$gt": "?"}}, "limit": "?"}`,
+			collection: "users",
+		},
+		{
+			name: "driver fields",
+			command: bson.D{
+				{Key: "delete", Value: "users"},
+				{Key: "lsid", Value: bson.D{{Key: "id", Value: "session"}}},
+				{Key: "txnNumber", Value: int64(1)},
+				{Key: "$db", Value: "test"},
+			},
+			want:       `{"delete": "users"}`,
+			collection: "users",
+		},
+		{
+			name: "arrays keep their first element",
+			command: bson.D{
+				{Key: "insert", Value: "users"},
+				{Key: "documents", Value: bson.A{bson.D{{Key: "name", Value: "ada"}}, bson.D{{Key: "name", Value: "grace"}}}},
+			},
+			want:       `{"insert": "users", "documents": [{"name": "?"}]}`,
+			collection: "users",
+		},
+		{
+			name: "getMore",
+			command: bson.D{
+				{Key: "getMore", Value: int64(42)},
+				{Key: "collection", Value: "users"},
+			},
+			want:       `{"getMore": "?", "collection": "users"}`,
+			collection: "users",
+		},
+		{
+			name:    "empty array",
+			command: bson.D{{Key: "ping", Value: 1}, {Key: "ids", Value: bson.A{}}},
+			want:    `{"ping": "?", "ids": []}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mongootel.SanitizeCommand(mustMarshal(t, tt.command), 0)
+			if got.Text != tt.want {
+				t.Errorf("Text = %s, want %s", got.Text, tt.want)
+			}
+			if got.Collection != tt.collection {
+				t.Errorf("Collection = %q, want %q", got.Collection, tt.collection)
+			}
+			if len(got.ShapeHash) != 16 {
+				t.Errorf("ShapeHash = %q, want 16 hex digits", got.ShapeHash)
+			}
+		})
+	}
+}
+
+func TestSanitizeCommandShapeHash(t *testing.T) {
+	in := func(ids ...any) bson.Raw {
+		return mustMarshal(t, bson.D{
+			{Key: "find", Value: "users"},
+			{Key: "filter", Value: bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: bson.A(ids)}}}}},
+		})
+	}
+
+	a := mongootel.SanitizeCommand(in(1, 2, 3), 0)
+	b := mongootel.SanitizeCommand(in(4), 0)
+	if a.ShapeHash != b.ShapeHash {
+		t.Errorf("$in lists of different sizes have shape hashes %s and %s, want equal", a.ShapeHash, b.ShapeHash)
+	}
+
+	other := mongootel.SanitizeCommand(mustMarshal(t, bson.D{
+		{Key: "find", Value: "users"},
+		{Key: "filter", Value: bson.D{{Key: "email", Value: "ada@example.com"}}},
+	}), 0)
+	if other.ShapeHash == a.ShapeHash {
+		t.Error("queries of different shapes have the same shape hash")
+	}
+}
+
+func TestSanitizeCommandTruncates(t *testing.T) {
+	command := mustMarshal(t, bson.D{
+		{Key: "find", Value: "users"},
+		{Key: "filter", Value: bson.D{{Key: strings.Repeat("a", 100), Value: 1}}},
+	})
+
+	full := mongootel.SanitizeCommand(command, 0)
+	got := mongootel.SanitizeCommand(command, 20)
+	if got.Text != full.Text[:20]+"..." {
+		t.Errorf("Text = %s, want the first 20 bytes and ...", got.Text)
+	}
+	if got.ShapeHash != full.ShapeHash {
+		t.Error("truncation changed the shape hash, want the hash of the full statement")
+	}
+}
@@ -0,0 +1,170 @@
+package mongootel
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	driversession "go.mongodb.org/mongo-driver/x/mongo/driver/session"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// transientTransactionError labels errors after which the whole transaction can be retried
+	transientTransactionError = "TransientTransactionError"
+	// unknownTransactionCommitResult labels commit errors after which the commit can be retried
+	unknownTransactionCommitResult = "UnknownTransactionCommitResult"
+	// transactionRetryTimeout bounds the retries of WithTransaction, as in the driver
+	transactionRetryTimeout = 120 * time.Second
+)
+
+// WithTransaction runs fn in a transaction inside a MongoDB.Transaction span.
+// Wrapped collection operations called with the callback context are children of
+// that span. Like the driver's Session.WithTransaction, the transaction is retried
+// on TransientTransactionError and the commit on UnknownTransactionCommitResult
+// for up to 120 seconds; commits, aborts and retries are recorded as span events.
+func (c *Client) WithTransaction(ctx context.Context, fn func(ctx mongo.SessionContext) (interface{}, error), opts ...*options.TransactionOptions) (interface{}, error) {
+	ctx, span := c.startSpan(ctx, "Transaction")
+	defer span.End()
+	defer c.recordDuration(ctx, "Transaction", time.Now())
+
+	session, err := c.client.StartSession()
+	if err != nil {
+		handleError(span, err)
+		return nil, err
+	}
+	defer session.EndSession(context.WithoutCancel(ctx))
+
+	result, err := runTransaction(ctx, span, session, fn, opts)
+	handleError(span, err)
+	return result, err
+}
+
+// UseSession runs fn with a new session inside a MongoDB.Session span. Wrapped
+// collection operations called with the callback context are children of that span.
+func (c *Client) UseSession(ctx context.Context, fn func(ctx mongo.SessionContext) error, opts ...*options.SessionOptions) error {
+	ctx, span := c.startSpan(ctx, "Session")
+	defer span.End()
+	defer c.recordDuration(ctx, "Session", time.Now())
+
+	session, err := c.client.StartSession(opts...)
+	if err != nil {
+		handleError(span, err)
+		return err
+	}
+	defer session.EndSession(context.WithoutCancel(ctx))
+
+	err = fn(mongo.NewSessionContext(ctx, session))
+	handleError(span, err)
+	return err
+}
+
+// runTransaction is the retry loop of WithTransaction
+func runTransaction(ctx context.Context, span trace.Span, session mongo.Session, fn func(ctx mongo.SessionContext) (interface{}, error), opts []*options.TransactionOptions) (interface{}, error) {
+	deadline := time.Now().Add(transactionRetryTimeout)
+	for attempt := 1; ; attempt++ {
+		span.SetAttributes(attribute.Int("db.mongodb.transaction.attempts", attempt))
+		if err := session.StartTransaction(opts...); err != nil {
+			return nil, err
+		}
+
+		result, err := fn(mongo.NewSessionContext(ctx, session))
+		if err != nil {
+			// Fails when the callback already aborted or committed the transaction
+			if session.AbortTransaction(context.WithoutCancel(ctx)) == nil {
+				span.AddEvent("transaction.abort", trace.WithAttributes(transactionEventAttributes(attempt, err)...))
+			}
+			if hasErrorLabel(err, transientTransactionError) && time.Now().Before(deadline) {
+				span.AddEvent("transaction.retry", trace.WithAttributes(transactionEventAttributes(attempt, err)...))
+				continue
+			}
+			return result, err
+		}
+
+		// A canceled context would fail the commit, abort instead
+		if ctx.Err() != nil {
+			_ = session.AbortTransaction(context.WithoutCancel(ctx))
+			span.AddEvent("transaction.abort", trace.WithAttributes(transactionEventAttributes(attempt, ctx.Err())...))
+			return nil, ctx.Err()
+		}
+
+		retry, err := commitTransaction(ctx, span, session, attempt, deadline)
+		if retry {
+			continue
+		}
+		return result, err
+	}
+}
+
+// commitTransaction commits the transaction, retrying commits with an unknown
+// result. retry is true when the whole transaction must run again.
+func commitTransaction(ctx context.Context, span trace.Span, session mongo.Session, attempt int, deadline time.Time) (retry bool, err error) {
+	for {
+		err = session.CommitTransaction(context.WithoutCancel(ctx))
+		if err == nil {
+			span.AddEvent("transaction.commit", trace.WithAttributes(attribute.Int("db.mongodb.transaction.attempt", attempt)))
+			return false, nil
+		}
+		if errors.Is(err, driversession.ErrCommitAfterAbort) {
+			// The callback aborted the transaction on purpose
+			return false, nil
+		}
+
+		span.AddEvent("transaction.commit.failed", trace.WithAttributes(transactionEventAttributes(attempt, err)...))
+		if time.Now().After(deadline) {
+			return false, err
+		}
+
+		var commandErr mongo.CommandError
+		if !errors.As(err, &commandErr) {
+			return false, err
+		}
+		if commandErr.HasErrorLabel(unknownTransactionCommitResult) && !commandErr.IsMaxTimeMSExpiredError() {
+			span.AddEvent("transaction.commit.retry", trace.WithAttributes(transactionEventAttributes(attempt, err)...))
+			continue
+		}
+		if commandErr.HasErrorLabel(transientTransactionError) {
+			span.AddEvent("transaction.retry", trace.WithAttributes(transactionEventAttributes(attempt, err)...))
+			return true, nil
+		}
+		return false, err
+	}
+}
+
+// transactionEventAttributes describes the attempt and the error of a transaction event
+func transactionEventAttributes(attempt int, err error) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.Int("db.mongodb.transaction.attempt", attempt),
+		attribute.String("error.message", err.Error()),
+	}
+	if labels := errorLabels(err); len(labels) > 0 {
+		attrs = append(attrs, attribute.StringSlice("db.mongodb.error_labels", labels))
+	}
+	return attrs
+}
+
+// errorLabels returns the labels of a server error
+func errorLabels(err error) []string {
+	var commandErr mongo.CommandError
+	if errors.As(err, &commandErr) {
+		return commandErr.Labels
+	}
+	var writeErr mongo.WriteException
+	if errors.As(err, &writeErr) {
+		return writeErr.Labels
+	}
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) {
+		return bulkErr.Labels
+	}
+	return nil
+}
+
+// hasErrorLabel reports whether err carries the label
+func hasErrorLabel(err error, label string) bool {
+	var labeled mongo.LabeledError
+	return errors.As(err, &labeled) && labeled.HasErrorLabel(label)
+}
@@ -0,0 +1,112 @@
+package mongootel_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Doraverse-Workspace/open-observe/mongootel"
+	"github.com/Doraverse-Workspace/open-observe/oteltest"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestWithTransactionRetries(t *testing.T) {
+	r := oteltest.NewLocal(t)
+	server := newFakeServer(t)
+	server.HandleSequence("insert",
+		bson.D{
+			{Key: "ok", Value: 0},
+			{Key: "code", Value: 251},
+			{Key: "codeName", Value: "NoSuchTransaction"},
+			{Key: "errmsg", Value: "transaction aborted"},
+			{Key: "errorLabels", Value: bson.A{"TransientTransactionError"}},
+		},
+		bson.D{{Key: "n", Value: 1}},
+	)
+	server.HandleSequence("commitTransaction",
+		bson.D{
+			{Key: "ok", Value: 0},
+			{Key: "code", Value: 6},
+			{Key: "codeName", Value: "HostUnreachable"},
+			{Key: "errmsg", Value: "commit result unknown"},
+			{Key: "errorLabels", Value: bson.A{"UnknownTransactionCommitResult"}},
+		},
+		bson.D{},
+	)
+	client := newTestClient(t, server, mongootel.WithTracerProvider(r.TracerProvider))
+	r.Reset()
+
+	users := client.Collection("users")
+	calls := 0
+	result, err := client.WithTransaction(context.Background(), func(ctx mongo.SessionContext) (interface{}, error) {
+		calls++
+		return users.InsertOne(ctx, bson.M{"name": "ada"})
+	})
+	if err != nil {
+		t.Fatalf("WithTransaction() error = %v", err)
+	}
+	if result == nil {
+		t.Error("WithTransaction() result = nil, want the InsertOne result")
+	}
+	if calls != 2 {
+		t.Errorf("callback ran %d times, want 2", calls)
+	}
+	if n := len(server.Commands("commitTransaction")); n != 2 {
+		t.Errorf("server received %d commitTransaction commands, want 2", n)
+	}
+	if n := len(server.Commands("abortTransaction")); n != 1 {
+		t.Errorf("server received %d abortTransaction commands, want 1", n)
+	}
+
+	spans := r.Spans()
+	spans.Find("MongoDB.Transaction").
+		IsRoot().
+		HasAttribute("db.mongodb.transaction.attempts", 2).
+		HasEvent("transaction.abort", attribute.Int("db.mongodb.transaction.attempt", 1)).
+		HasEvent("transaction.retry",
+			attribute.Int("db.mongodb.transaction.attempt", 1),
+			attribute.StringSlice("db.mongodb.error_labels", []string{"TransientTransactionError"})).
+		HasEvent("transaction.commit.failed", attribute.Int("db.mongodb.transaction.attempt", 2)).
+		HasEvent("transaction.commit.retry", attribute.Int("db.mongodb.transaction.attempt", 2)).
+		HasEvent("transaction.commit", attribute.Int("db.mongodb.transaction.attempt", 2)).
+		HasStatus(codes.Ok)
+	if inserts := spans.Named("MongoDB.InsertOne"); inserts.Len() != 2 {
+		t.Errorf("%d MongoDB.InsertOne spans, want 2", inserts.Len())
+	}
+	for _, insert := range spans.Named("MongoDB.InsertOne").All() {
+		spans.Assert(insert).HasParent("MongoDB.Transaction")
+	}
+}
+
+func TestWithTransactionCallbackError(t *testing.T) {
+	r := oteltest.NewLocal(t)
+	server := newFakeServer(t)
+	server.Handle("insert", func(bson.Raw) bson.D {
+		return bson.D{
+			{Key: "ok", Value: 0},
+			{Key: "code", Value: 2},
+			{Key: "codeName", Value: "BadValue"},
+			{Key: "errmsg", Value: "bad document"},
+		}
+	})
+	client := newTestClient(t, server, mongootel.WithTracerProvider(r.TracerProvider))
+	r.Reset()
+
+	users := client.Collection("users")
+	_, err := client.WithTransaction(context.Background(), func(ctx mongo.SessionContext) (interface{}, error) {
+		return users.InsertOne(ctx, bson.M{"name": "ada"})
+	})
+	if err == nil {
+		t.Fatal("WithTransaction() succeeded, want the insert error")
+	}
+	if n := len(server.Commands("commitTransaction")); n != 0 {
+		t.Errorf("server received %d commitTransaction commands, want 0", n)
+	}
+
+	r.Find("MongoDB.Transaction").
+		HasAttribute("db.mongodb.transaction.attempts", 1).
+		HasEvent("transaction.abort").
+		HasStatus(codes.Error)
+}
@@ -0,0 +1,186 @@
+package otel
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	errorType    = reflect.TypeOf((*error)(nil)).Elem()
+	stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// StructAttributes flattens a struct, a map with string keys or a pointer to them
+// into span attributes. Empty values are omitted.
+//
+// The attribute key of a field is the name of its json tag, or the snake_case
+// field name when it has none. Fields tagged json:"-" and unexported fields are
+// skipped. Nested structs and maps are flattened with their key as prefix,
+// e.g. a field Address with a field City gives "address.city"; embedded structs
+// without a json name are flattened without prefix.
+func StructAttributes(v any) []attribute.KeyValue {
+	return appendAttributes(nil, "", reflect.ValueOf(v))
+}
+
+// appendAttributes appends the attributes of v under key to attrs
+func appendAttributes(attrs []attribute.KeyValue, key string, v reflect.Value) []attribute.KeyValue {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return attrs
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || v.IsZero() {
+		return attrs
+	}
+
+	t := v.Type()
+	switch {
+	case t == timeType:
+		return append(attrs, attribute.String(key, v.Interface().(time.Time).Format(time.RFC3339)))
+	case t == durationType:
+		return append(attrs, attribute.String(key, v.Interface().(time.Duration).String()))
+	case t.Implements(errorType):
+		return append(attrs, attribute.String(key, v.Interface().(error).Error()))
+	case t.Kind() != reflect.Struct && t.Implements(stringerType):
+		return append(attrs, attribute.String(key, v.Interface().(fmt.Stringer).String()))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return append(attrs, attribute.String(key, v.String()))
+	case reflect.Bool:
+		return append(attrs, attribute.Bool(key, v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return append(attrs, attribute.Int64(key, v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return append(attrs, attribute.Int64(key, int64(v.Uint())))
+	case reflect.Float32, reflect.Float64:
+		return append(attrs, attribute.Float64(key, v.Float()))
+	case reflect.Struct:
+		return appendStructAttributes(attrs, key, v)
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return append(attrs, attribute.String(key, fmt.Sprint(v.Interface())))
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			attrs = appendAttributes(attrs, joinKey(key, iter.Key().String()), iter.Value())
+		}
+		return attrs
+	case reflect.Slice, reflect.Array:
+		return append(attrs, sliceAttribute(key, v))
+	default:
+		return append(attrs, attribute.String(key, fmt.Sprint(v.Interface())))
+	}
+}
+
+// appendStructAttributes appends the exported fields of the struct v
+func appendStructAttributes(attrs []attribute.KeyValue, prefix string, v reflect.Value) []attribute.KeyValue {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, ok := fieldKey(field)
+		if !ok {
+			continue
+		}
+
+		key := joinKey(prefix, name)
+		if field.Anonymous && name == "" {
+			key = prefix
+		}
+		attrs = appendAttributes(attrs, key, v.Field(i))
+	}
+	return attrs
+}
+
+// fieldKey returns the attribute key of a struct field and false when it is skipped.
+// Embedded fields without a json name return an empty key.
+func fieldKey(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+
+	name, _, _ := strings.Cut(tag, ",")
+	if name != "" {
+		return name, true
+	}
+	if field.Anonymous {
+		return "", true
+	}
+	return snakeCase(field.Name), true
+}
+
+// sliceAttribute converts slices of basic types to typed slice attributes
+func sliceAttribute(key string, v reflect.Value) attribute.KeyValue {
+	switch v.Type().Elem().Kind() {
+	case reflect.String:
+		values := make([]string, v.Len())
+		for i := range values {
+			values[i] = v.Index(i).String()
+		}
+		return attribute.StringSlice(key, values)
+	case reflect.Bool:
+		values := make([]bool, v.Len())
+		for i := range values {
+			values[i] = v.Index(i).Bool()
+		}
+		return attribute.BoolSlice(key, values)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		values := make([]int64, v.Len())
+		for i := range values {
+			values[i] = v.Index(i).Int()
+		}
+		return attribute.Int64Slice(key, values)
+	case reflect.Float32, reflect.Float64:
+		values := make([]float64, v.Len())
+		for i := range values {
+			values[i] = v.Index(i).Float()
+		}
+		return attribute.Float64Slice(key, values)
+	default:
+		return attribute.String(key, fmt.Sprint(v.Interface()))
+	}
+}
+
+// joinKey joins an attribute key prefix and name with a dot
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	if name == "" {
+		return prefix
+	}
+	return prefix + "." + name
+}
+
+// snakeCase converts a Go field name to snake_case, e.g. UserID to user_id
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			prevLower := i > 0 && !unicode.IsUpper(runes[i-1])
+			nextLower := i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || nextLower {
+				sb.WriteByte('_')
+			}
+			sb.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -2,4 +2,5 @@ package otel
 
 const (
 	TraceContextKey = "trace_context"
+	TraceDataKey    = "trace_data"
 )
@@ -0,0 +1,239 @@
+package otel
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxErrorCauses bounds the number of causes recorded for a single error
+const maxErrorCauses = 32
+
+// ErrorKind tells whether an error is the fault of the client or of the server
+type ErrorKind int
+
+const (
+	// ErrorKindUnknown lets RecordError derive the kind from the status code
+	ErrorKindUnknown ErrorKind = iota
+	// ErrorKindClient is an error caused by the request, e.g. invalid input
+	ErrorKindClient
+	// ErrorKindServer is an error caused by the service or one of its dependencies
+	ErrorKindServer
+)
+
+// String returns the value recorded in the error.kind attribute
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrorKindClient:
+		return "client"
+	case ErrorKindServer:
+		return "server"
+	default:
+		return "unknown"
+	}
+}
+
+// ErrorOption configures RecordError
+type ErrorOption func(*errorConfig)
+
+type errorConfig struct {
+	kind       ErrorKind
+	statusCode int
+	skip       int
+	classifier ErrorClassifier
+	mode       SemConvMode
+}
+
+// WithErrorKind classifies the error as a client or server fault
+func WithErrorKind(kind ErrorKind) ErrorOption {
+	return func(c *errorConfig) {
+		c.kind = kind
+	}
+}
+
+// WithStatusCode sets the status code recorded for the error
+func WithStatusCode(code int) ErrorOption {
+	return func(c *errorConfig) {
+		c.statusCode = code
+	}
+}
+
+// WithErrorClassifier classifies the error with classifier instead of the registered one
+func WithErrorClassifier(classifier ErrorClassifier) ErrorOption {
+	return func(c *errorConfig) {
+		c.classifier = classifier
+	}
+}
+
+// withSemConvMode sets the semconv mode of the status code attribute
+func withSemConvMode(mode SemConvMode) ErrorOption {
+	return func(c *errorConfig) {
+		c.mode = mode
+	}
+}
+
+// WithStackSkip skips additional stack frames, for helpers that wrap RecordError
+func WithStackSkip(skip int) ErrorOption {
+	return func(c *errorConfig) {
+		c.skip += skip
+	}
+}
+
+// RecordError records err on the span as an exception event carrying the Go type,
+// the stack at the call site and each wrapped cause (errors.Unwrap and errors.Join),
+// then sets the status code. Only server faults set the span status to Error.
+//
+// When no kind is given the ErrorClassifier decides it, falling back to the default
+// rules when it has no opinion, and errors it classifies as ignorable or as a
+// success are not recorded. The status code attribute is only set when a status
+// code is given or comes from an *echo.HTTPError in the chain, so errors recorded
+// on database or worker spans only carry error.kind.
+func RecordError(span trace.Span, err error, opts ...ErrorOption) {
+	if err == nil {
+		return
+	}
+
+	cfg := errorConfig{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.classifier == nil {
+		cfg.classifier = GetErrorClassifier()
+	}
+
+	var httpErr *echo.HTTPError
+	if cfg.statusCode == 0 && errors.As(err, &httpErr) {
+		cfg.statusCode = httpErr.Code
+	}
+
+	if cfg.kind == ErrorKindUnknown {
+		switch classifyWithDefaults(cfg.classifier, err, cfg.statusCode) {
+		case ErrorClassIgnore, ErrorClassSuccess:
+			// The classifier does not consider it an error
+			return
+		case ErrorClassClient:
+			cfg.kind = ErrorKindClient
+		case ErrorClassError:
+			cfg.kind = ErrorKindServer
+		}
+	}
+	hasStatusCode := cfg.statusCode != 0
+	cfg.statusCode, cfg.kind = resolveErrorStatus(cfg.statusCode, cfg.kind)
+
+	span.AddEvent("exception", trace.WithAttributes(
+		attribute.String("exception.type", errorTypeName(err)),
+		attribute.String("exception.message", err.Error()),
+		attribute.String("exception.stacktrace", callerStack(2+cfg.skip)),
+		attribute.String("error.kind", cfg.kind.String()),
+	))
+
+	for i, cause := range errorCauses(err) {
+		span.AddEvent("exception.cause", trace.WithAttributes(
+			attribute.Int("exception.cause.index", i),
+			attribute.String("exception.type", errorTypeName(cause)),
+			attribute.String("exception.message", cause.Error()),
+		))
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("error.message", err.Error()),
+		attribute.String("error.kind", cfg.kind.String()),
+	}
+	if hasStatusCode {
+		attrs = append(attrs, legacyStatusCodeKey.Int(cfg.statusCode))
+	}
+	span.SetAttributes(cfg.mode.Convert(attrs)...)
+	if cfg.kind == ErrorKindServer {
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// resolveErrorStatus fills in whichever of the status code and error kind is still unknown
+func resolveErrorStatus(statusCode int, kind ErrorKind) (int, ErrorKind) {
+	if statusCode == 0 {
+		if kind == ErrorKindClient {
+			statusCode = http.StatusBadRequest
+		} else {
+			statusCode = http.StatusInternalServerError
+		}
+	}
+
+	if kind == ErrorKindUnknown {
+		if statusCode >= 400 && statusCode < 500 {
+			kind = ErrorKindClient
+		} else {
+			kind = ErrorKindServer
+		}
+	}
+
+	return statusCode, kind
+}
+
+// errorCauses walks the error tree depth first and returns every wrapped error,
+// excluding err itself
+func errorCauses(err error) []error {
+	var causes []error
+	var walk func(error)
+	walk = func(e error) {
+		if len(causes) >= maxErrorCauses {
+			return
+		}
+		switch x := e.(type) {
+		case interface{ Unwrap() error }:
+			if inner := x.Unwrap(); inner != nil {
+				causes = append(causes, inner)
+				walk(inner)
+			}
+		case interface{ Unwrap() []error }:
+			for _, inner := range x.Unwrap() {
+				if inner == nil || len(causes) >= maxErrorCauses {
+					continue
+				}
+				causes = append(causes, inner)
+				walk(inner)
+			}
+		}
+	}
+	walk(err)
+	return causes
+}
+
+// errorTypeName returns the fully qualified Go type name of err, e.g.
+// *go.mongodb.org/mongo-driver/mongo.CommandError
+func errorTypeName(err error) string {
+	t := reflect.TypeOf(err)
+	prefix := ""
+	for t.Kind() == reflect.Ptr {
+		prefix += "*"
+		t = t.Elem()
+	}
+	if t.PkgPath() == "" || t.Name() == "" {
+		return prefix + t.String()
+	}
+	return prefix + t.PkgPath() + "." + t.Name()
+}
+
+// callerStack formats the stack of the caller, skip frames above callerStack
+func callerStack(skip int) string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+1, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var sb strings.Builder
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&sb, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,162 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrorClass is the outcome of classifying an error or a status code
+type ErrorClass int
+
+const (
+	// ErrorClassUnknown means the classifier has no opinion, the next rule decides
+	ErrorClassUnknown ErrorClass = iota
+	// ErrorClassSuccess is not an error
+	ErrorClassSuccess
+	// ErrorClassError is a real error, the span status is set to Error
+	ErrorClassError
+	// ErrorClassClient is a fault of the client, it is recorded but does not mark
+	// the request span as failed
+	ErrorClassClient
+	// ErrorClassIgnore is an expected error that is not recorded at all,
+	// e.g. a canceled request or a lookup that found nothing
+	ErrorClassIgnore
+)
+
+// String returns the name of the class
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorClassSuccess:
+		return "success"
+	case ErrorClassError:
+		return "error"
+	case ErrorClassClient:
+		return "client"
+	case ErrorClassIgnore:
+		return "ignore"
+	default:
+		return "unknown"
+	}
+}
+
+// ErrorClassifier decides whether an error or a status code is a real error,
+// a client fault or ignorable. err may be nil and statusCode may be 0.
+type ErrorClassifier interface {
+	Classify(err error, statusCode int) ErrorClass
+}
+
+// ErrorClassifierFunc adapts a function to the ErrorClassifier interface
+type ErrorClassifierFunc func(err error, statusCode int) ErrorClass
+
+// Classify calls f(err, statusCode)
+func (f ErrorClassifierFunc) Classify(err error, statusCode int) ErrorClass {
+	return f(err, statusCode)
+}
+
+// ErrorRules is an ErrorClassifier that asks each rule in order and returns the
+// first answer other than ErrorClassUnknown. When no rule answers, any error or
+// a status code of 400 and above is an error.
+type ErrorRules []ErrorClassifier
+
+// Classify implements ErrorClassifier
+func (r ErrorRules) Classify(err error, statusCode int) ErrorClass {
+	for _, rule := range r {
+		if class := rule.Classify(err, statusCode); class != ErrorClassUnknown {
+			return class
+		}
+	}
+
+	if err != nil || statusCode >= http.StatusBadRequest {
+		return ErrorClassError
+	}
+	return ErrorClassSuccess
+}
+
+var (
+	// ContextCanceledRule ignores requests canceled by the client
+	ContextCanceledRule ErrorClassifier = ErrorClassifierFunc(func(err error, _ int) ErrorClass {
+		if errors.Is(err, context.Canceled) {
+			return ErrorClassIgnore
+		}
+		return ErrorClassUnknown
+	})
+
+	// MongoNoDocumentsRule ignores mongo.ErrNoDocuments returned by FindOne
+	MongoNoDocumentsRule ErrorClassifier = ErrorClassifierFunc(func(err error, _ int) ErrorClass {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrorClassIgnore
+		}
+		return ErrorClassUnknown
+	})
+
+	// EchoNotFoundRule classifies requests to unknown routes as client faults
+	EchoNotFoundRule ErrorClassifier = ErrorClassifierFunc(func(err error, _ int) ErrorClass {
+		if errors.Is(err, echo.ErrNotFound) {
+			return ErrorClassClient
+		}
+		return ErrorClassUnknown
+	})
+
+	// ClientErrorStatusRule classifies 4xx responses as client faults
+	ClientErrorStatusRule ErrorClassifier = ErrorClassifierFunc(func(_ error, statusCode int) ErrorClass {
+		if statusCode >= http.StatusBadRequest && statusCode < http.StatusInternalServerError {
+			return ErrorClassClient
+		}
+		return ErrorClassUnknown
+	})
+)
+
+// DefaultErrorClassifier returns the built-in rules: canceled contexts and
+// mongo.ErrNoDocuments are ignored, echo.ErrNotFound and 4xx responses are client faults.
+func DefaultErrorClassifier() ErrorRules {
+	return ErrorRules{
+		ContextCanceledRule,
+		MongoNoDocumentsRule,
+		EchoNotFoundRule,
+		ClientErrorStatusRule,
+	}
+}
+
+type classifierHolder struct {
+	classifier ErrorClassifier
+}
+
+var globalErrorClassifier atomic.Value
+
+func init() {
+	globalErrorClassifier.Store(classifierHolder{classifier: DefaultErrorClassifier()})
+}
+
+// SetErrorClassifier registers the classifier used by AddTraceAttributes, RecordError
+// and the instrumentation of this module. InitTracerHTTP and InitTracerGRPC call it
+// with Config.ErrorClassifier when set.
+func SetErrorClassifier(classifier ErrorClassifier) {
+	if classifier == nil {
+		classifier = DefaultErrorClassifier()
+	}
+	globalErrorClassifier.Store(classifierHolder{classifier: classifier})
+}
+
+// GetErrorClassifier returns the registered classifier
+func GetErrorClassifier() ErrorClassifier {
+	return globalErrorClassifier.Load().(classifierHolder).classifier
+}
+
+// ClassifyError classifies err and statusCode with the registered classifier
+func ClassifyError(err error, statusCode int) ErrorClass {
+	return classifyWithDefaults(GetErrorClassifier(), err, statusCode)
+}
+
+// classifyWithDefaults asks classifier, then the default rules when it returns
+// ErrorClassUnknown, so a custom classifier only needs to handle its own errors
+func classifyWithDefaults(classifier ErrorClassifier, err error, statusCode int) ErrorClass {
+	if class := classifier.Classify(err, statusCode); class != ErrorClassUnknown {
+		return class
+	}
+	return DefaultErrorClassifier().Classify(err, statusCode)
+}
@@ -0,0 +1,140 @@
+package otel_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	tel "github.com/Doraverse-Workspace/open-observe/otel"
+	"github.com/Doraverse-Workspace/open-observe/oteltest"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestRecordErrorStatusCode(t *testing.T) {
+	errNotFound := errors.New("user not found")
+
+	tests := []struct {
+		name       string
+		err        error
+		opts       []tel.ErrorOption
+		statusCode int
+		kind       string
+		status     codes.Code
+	}{
+		{
+			name:   "plain error",
+			err:    errors.New("connection reset"),
+			kind:   "server",
+			status: codes.Error,
+		},
+		{
+			name:   "client kind",
+			err:    errNotFound,
+			opts:   []tel.ErrorOption{tel.WithErrorKind(tel.ErrorKindClient)},
+			kind:   "client",
+			status: codes.Unset,
+		},
+		{
+			name:       "status code",
+			err:        errNotFound,
+			opts:       []tel.ErrorOption{tel.WithStatusCode(http.StatusNotFound)},
+			statusCode: http.StatusNotFound,
+			kind:       "client",
+			status:     codes.Unset,
+		},
+		{
+			name:       "echo error",
+			err:        fmt.Errorf("handler: %w", echo.NewHTTPError(http.StatusBadGateway, "upstream failed")),
+			statusCode: http.StatusBadGateway,
+			kind:       "server",
+			status:     codes.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := oteltest.NewLocal(t)
+			_, span := r.Tracer("test").Start(context.Background(), "operation")
+			tel.RecordError(span, tt.err, tt.opts...)
+			span.End()
+
+			assert := r.Find("operation").
+				HasAttribute("error.kind", tt.kind).
+				HasAttribute("error.message", tt.err.Error()).
+				HasEvent("exception", attribute.String("error.kind", tt.kind)).
+				HasStatus(tt.status)
+			if tt.statusCode != 0 {
+				assert.HasAttribute("status.code", tt.statusCode)
+			} else {
+				assert.LacksAttribute("status.code").LacksAttribute("http.response.status_code")
+			}
+		})
+	}
+}
+
+func TestRecordErrorCustomClassifier(t *testing.T) {
+	errRetry := errors.New("retry later")
+	errBusy := errors.New("resource busy")
+	classifier := tel.ErrorClassifierFunc(func(err error, _ int) tel.ErrorClass {
+		switch {
+		case errors.Is(err, errRetry):
+			return tel.ErrorClassSuccess
+		case errors.Is(err, errBusy):
+			return tel.ErrorClassIgnore
+		}
+		return tel.ErrorClassUnknown
+	})
+
+	tests := []struct {
+		name     string
+		err      error
+		recorded bool
+		status   codes.Code
+	}{
+		{name: "success", err: fmt.Errorf("sync: %w", errRetry), status: codes.Unset},
+		{name: "ignore", err: errBusy, status: codes.Unset},
+		{name: "unknown falls back to the defaults", err: context.Canceled, status: codes.Unset},
+		{name: "unknown error", err: errors.New("disk full"), recorded: true, status: codes.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := oteltest.NewLocal(t)
+			_, span := r.Tracer("test").Start(context.Background(), "operation")
+			tel.RecordError(span, tt.err, tel.WithErrorClassifier(classifier))
+			span.End()
+
+			assert := r.Find("operation").HasStatus(tt.status)
+			if tt.recorded {
+				assert.HasEvent("exception").HasAttribute("error.kind", "server")
+			} else {
+				assert.LacksAttribute("error.kind").LacksAttribute("error.message")
+				if n := len(assert.Span().Events()); n != 0 {
+					t.Errorf("%d events recorded, want none", n)
+				}
+			}
+		})
+	}
+}
+
+func TestOtelMiddlewareSuccessClassifiedError(t *testing.T) {
+	r := oteltest.New(t)
+	errDraining := errors.New("draining")
+	classifier := tel.ErrorClassifierFunc(func(err error, _ int) tel.ErrorClass {
+		if errors.Is(err, errDraining) {
+			return tel.ErrorClassSuccess
+		}
+		return tel.ErrorClassUnknown
+	})
+
+	_, spans := r.ServeEcho(tel.Config{ServiceName: "users", ErrorClassifier: classifier}, "/drain", func(c echo.Context) error {
+		return errDraining
+	}, httptest.NewRequest(http.MethodPost, "/drain", nil))
+
+	spans.Find("/drain").HasStatus(codes.Ok).LacksAttribute("error.kind")
+}
@@ -7,9 +7,7 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/propagation"
-	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
 )
 
 func InitTracerGRPC(config Config) *sdktrace.TracerProvider {
@@ -29,6 +27,7 @@ func InitTracerGRPC(config Config) *sdktrace.TracerProvider {
 		otlptracegrpc.WithEndpoint(gprcEndpoint),
 		otlptracegrpc.WithHeaders(map[string]string{
 			"Authorization": "Basic " + config.BasicAuth,
+			"organization":  config.organization(),
 			"stream-name":   streamName,
 		}),
 	}
@@ -43,11 +42,7 @@ func InitTracerGRPC(config Config) *sdktrace.TracerProvider {
 		fmt.Println("Error creating HTTP OTLP exporter: ", err)
 	}
 
-	res := resource.NewWithAttributes(
-		semconv.SchemaURL,
-		// the service name used to display traces in backends
-		semconv.ServiceNameKey.String(config.ServiceName),
-	)
+	res := newResource(config)
 
 	tp := sdktrace.NewTracerProvider(
 		sdktrace.WithSampler(sdktrace.AlwaysSample()),
@@ -55,6 +50,12 @@ func InitTracerGRPC(config Config) *sdktrace.TracerProvider {
 		sdktrace.WithBatcher(otlpGRPCExporter),
 	)
 	otel.SetTracerProvider(tp)
+	if config.ErrorClassifier != nil {
+		SetErrorClassifier(config.ErrorClassifier)
+	}
+	if config.SemConvMode != SemConvDefault {
+		SetSemConvMode(config.SemConvMode)
+	}
 	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
 	return tp
 }
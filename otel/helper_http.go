@@ -5,12 +5,9 @@ import (
 	"fmt"
 
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/propagation"
-	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -25,7 +22,7 @@ func InitTracerHTTP(config Config) *sdktrace.TracerProvider {
 		httpEndpoint = config.Endpoint
 	}
 
-	path := "/api/default/v1/traces"
+	path := "/api/" + config.organization() + "/v1/traces"
 	streamName := "default"
 	if config.StreamName != "" {
 		streamName = config.StreamName
@@ -54,12 +51,7 @@ func InitTracerHTTP(config Config) *sdktrace.TracerProvider {
 		fmt.Println("Error creating HTTP OTLP exporter: ", err)
 	}
 
-	res := resource.NewWithAttributes(
-		semconv.SchemaURL,
-		semconv.ServiceNameKey.String(config.ServiceName),
-		semconv.ServiceVersionKey.String("0.0.1"),
-		attribute.String("environment", config.Environment),
-	)
+	res := newResource(config)
 
 	tp := sdktrace.NewTracerProvider(
 		sdktrace.WithSampler(sdktrace.AlwaysSample()),
@@ -67,6 +59,12 @@ func InitTracerHTTP(config Config) *sdktrace.TracerProvider {
 		sdktrace.WithBatcher(otlpHTTPExporter),
 	)
 	otel.SetTracerProvider(tp)
+	if config.ErrorClassifier != nil {
+		SetErrorClassifier(config.ErrorClassifier)
+	}
+	if config.SemConvMode != SemConvDefault {
+		SetSemConvMode(config.SemConvMode)
+	}
 
 	return tp
 }
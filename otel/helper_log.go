@@ -0,0 +1,56 @@
+package otel
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/log/global"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// InitLoggerHTTP initializes a logger provider that exports log records over
+// OTLP/HTTP to the OpenObserve logs endpoint and sets it as the global logger provider.
+func InitLoggerHTTP(config Config) *sdklog.LoggerProvider {
+	httpEndpoint := "127.0.0.1:5081"
+	if config.Endpoint != "" {
+		httpEndpoint = config.Endpoint
+	}
+
+	path := "/api/" + config.organization() + "/v1/logs"
+	streamName := "default"
+	if config.StreamName != "" {
+		streamName = config.StreamName
+	}
+
+	opts := []otlploghttp.Option{
+		otlploghttp.WithEndpoint(httpEndpoint),
+		otlploghttp.WithURLPath(path),
+		otlploghttp.WithHeaders(map[string]string{
+			"Authorization": "Basic " + config.BasicAuth,
+			"stream-name":   streamName,
+		}),
+	}
+
+	if !config.IsSecure {
+		opts = append(opts, otlploghttp.WithInsecure())
+	}
+
+	res := newResource(config)
+
+	providerOpts := []sdklog.LoggerProviderOption{
+		sdklog.WithResource(res),
+	}
+
+	otlpHTTPExporter, err := otlploghttp.New(context.TODO(), opts...)
+	if err != nil {
+		fmt.Println("Error creating HTTP OTLP log exporter: ", err)
+	} else {
+		providerOpts = append(providerOpts, sdklog.WithProcessor(sdklog.NewBatchProcessor(otlpHTTPExporter)))
+	}
+
+	lp := sdklog.NewLoggerProvider(providerOpts...)
+	global.SetLoggerProvider(lp)
+
+	return lp
+}
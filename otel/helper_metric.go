@@ -0,0 +1,107 @@
+package otel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/exemplar"
+)
+
+// DurationBuckets are the histogram bucket boundaries, in seconds, used for
+// request and operation duration metrics.
+var DurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.25, 0.5, 0.75, 1, 2.5, 5, 7.5, 10}
+
+// InitMeterHTTP initializes a meter provider that exports metrics over OTLP/HTTP.
+// Histograms record exemplars carrying the trace and span IDs of sampled requests,
+// so a latency spike can be followed to the trace that caused it.
+func InitMeterHTTP(config Config) *sdkmetric.MeterProvider {
+	httpEndpoint := "127.0.0.1:5081"
+	if config.Endpoint != "" {
+		httpEndpoint = config.Endpoint
+	}
+
+	path := "/api/" + config.organization() + "/v1/metrics"
+	streamName := "default"
+	if config.StreamName != "" {
+		streamName = config.StreamName
+	}
+
+	opts := []otlpmetrichttp.Option{
+		otlpmetrichttp.WithEndpoint(httpEndpoint),
+		otlpmetrichttp.WithURLPath(path),
+		otlpmetrichttp.WithHeaders(map[string]string{
+			"Authorization": "Basic " + config.BasicAuth,
+			"stream-name":   streamName,
+		}),
+	}
+
+	if !config.IsSecure {
+		opts = append(opts, otlpmetrichttp.WithInsecure())
+	}
+
+	otlpHTTPExporter, err := otlpmetrichttp.New(context.TODO(), opts...)
+	if err != nil {
+		fmt.Println("Error creating HTTP OTLP metric exporter: ", err)
+		return newMeterProvider(config, nil)
+	}
+
+	return newMeterProvider(config, otlpHTTPExporter)
+}
+
+// InitMeterGRPC initializes a meter provider that exports metrics over OTLP/gRPC.
+func InitMeterGRPC(config Config) *sdkmetric.MeterProvider {
+	gprcEndpoint := "127.0.0.1:5081"
+	if config.Endpoint != "" {
+		gprcEndpoint = config.Endpoint
+	}
+
+	streamName := "default"
+	if config.StreamName != "" {
+		streamName = config.StreamName
+	}
+
+	opts := []otlpmetricgrpc.Option{
+		otlpmetricgrpc.WithEndpoint(gprcEndpoint),
+		otlpmetricgrpc.WithHeaders(map[string]string{
+			"Authorization": "Basic " + config.BasicAuth,
+			"organization":  config.organization(),
+			"stream-name":   streamName,
+		}),
+	}
+
+	if !config.IsSecure {
+		opts = append(opts, otlpmetricgrpc.WithInsecure())
+	}
+
+	otlpGRPCExporter, err := otlpmetricgrpc.New(context.TODO(), opts...)
+	if err != nil {
+		fmt.Println("Error creating gRPC OTLP metric exporter: ", err)
+		return newMeterProvider(config, nil)
+	}
+
+	return newMeterProvider(config, otlpGRPCExporter)
+}
+
+func newMeterProvider(config Config, exporter sdkmetric.Exporter) *sdkmetric.MeterProvider {
+	res := newResource(config)
+
+	opts := []sdkmetric.Option{
+		sdkmetric.WithResource(res),
+		// Only measurements made inside a sampled span carry an exemplar, the
+		// others would point to a trace that was never exported.
+		sdkmetric.WithExemplarFilter(exemplar.TraceBasedFilter),
+	}
+	if exporter != nil {
+		opts = append(opts, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(15*time.Second))))
+	}
+
+	mp := sdkmetric.NewMeterProvider(opts...)
+	otel.SetMeterProvider(mp)
+
+	return mp
+}
@@ -0,0 +1,48 @@
+package otel
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// serverMetrics holds the instruments recorded by OtelMiddleware
+type serverMetrics struct {
+	requestDuration metric.Float64Histogram
+}
+
+// newServerMetrics creates the HTTP server instruments from the global meter provider
+func newServerMetrics(serviceName string) *serverMetrics {
+	meter := otel.Meter(serviceName)
+
+	requestDuration, err := meter.Float64Histogram(
+		"http.server.request.duration",
+		metric.WithDescription("Duration of HTTP server requests."),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(DurationBuckets...),
+	)
+	if err != nil {
+		fmt.Println("Error creating http.server.request.duration histogram: ", err)
+	}
+
+	return &serverMetrics{
+		requestDuration: requestDuration,
+	}
+}
+
+// recordRequest records the request duration. ctx must carry the request span so
+// that the exemplar points to it.
+func (m *serverMetrics) recordRequest(ctx context.Context, traceData TraceData) {
+	if m.requestDuration == nil {
+		return
+	}
+
+	m.requestDuration.Record(ctx, traceData.Duration/1000, metric.WithAttributes(
+		attribute.String("http.request.method", traceData.Action),
+		attribute.String("http.route", traceData.Resource),
+		attribute.Int("http.response.status_code", traceData.StatusCode),
+	))
+}
@@ -0,0 +1,74 @@
+package otel_test
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	tel "github.com/Doraverse-Workspace/open-observe/otel"
+	"github.com/Doraverse-Workspace/open-observe/oteltest/collector"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestRequestDurationExemplars(t *testing.T) {
+	c := collector.New(t)
+	mp := tel.InitMeterHTTP(tel.Config{ServiceName: "users", Endpoint: c.HTTPEndpoint()})
+	t.Cleanup(func() {
+		_ = mp.Shutdown(context.Background())
+	})
+
+	// The default sampler follows the sampled flag of the incoming traceparent
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	tp := sdktrace.NewTracerProvider()
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+		_ = tp.Shutdown(context.Background())
+	})
+
+	e := echo.New()
+	e.Use(tel.OtelMiddleware(tel.Config{ServiceName: "users"}))
+	ok := func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+	e.GET("/sampled", ok)
+	e.GET("/unsampled", ok)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	for route, flags := range map[string]string{"/sampled": "01", "/unsampled": "00"} {
+		req := httptest.NewRequest(http.MethodGet, route, nil)
+		req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-"+flags)
+		e.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if err := mp.ForceFlush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	metric := c.Metric("http.server.request.duration")
+	if metric == nil {
+		t.Fatalf("metrics = %v, want http.server.request.duration", c.Metrics())
+	}
+	exemplars := map[string]int{}
+	for _, dp := range metric.GetHistogram().GetDataPoints() {
+		route := collector.Attributes(dp.GetAttributes())["http.route"].(string)
+		for _, exemplar := range dp.GetExemplars() {
+			if got := hex.EncodeToString(exemplar.GetTraceId()); got != traceID {
+				t.Errorf("exemplar of %s has trace ID %s, want %s", route, got, traceID)
+			}
+			if len(exemplar.GetSpanId()) != 8 {
+				t.Errorf("exemplar of %s has span ID %x, want the request span", route, exemplar.GetSpanId())
+			}
+		}
+		exemplars[route] = len(dp.GetExemplars())
+	}
+	if exemplars["/sampled"] != 1 || exemplars["/unsampled"] != 0 {
+		t.Errorf("exemplars by route = %v, want 1 for /sampled and 0 for /unsampled", exemplars)
+	}
+}
@@ -1,24 +1,52 @@
 package otel
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/propagation"
-	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
+// legacyHTTPRequestIDKey has no replacement in the semantic conventions, it is
+// emitted in every mode
+const legacyHTTPRequestIDKey = attribute.Key("http.request_id")
+
+// MiddlewareOption configures OtelMiddleware
+type MiddlewareOption func(*middlewareOptions)
+
+type middlewareOptions struct {
+	userExtractor UserExtractor
+	userBaggage   bool
+}
+
 // OtelMiddleware returns a middleware that will trace incoming requests.
-func OtelMiddleware(config Config) echo.MiddlewareFunc {
+// The request's TraceData is stored in the Echo context under TraceDataKey and
+// completed when the request ends, it is the source of the span attributes,
+// the request metrics and the request log record.
+func OtelMiddleware(config Config, opts ...MiddlewareOption) echo.MiddlewareFunc {
 	if config.ServiceName == "" {
 		config.ServiceName = "default"
 	}
+	options := middlewareOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
 	tracer := otel.Tracer(config.ServiceName)
 	propagator := otel.GetTextMapPropagator()
+	metrics := newServerMetrics(config.ServiceName)
+	classifier := config.ErrorClassifier
+	if classifier == nil {
+		classifier = GetErrorClassifier()
+	}
+	mode := config.SemConvMode.orGlobal()
 
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -28,41 +56,29 @@ func OtelMiddleware(config Config) echo.MiddlewareFunc {
 			// Extract trace information from the incoming request
 			ctx = propagator.Extract(ctx, propagation.HeaderCarrier(req.Header))
 
-			path := c.Path()
-			route := path
-			if route == "" {
-				route = fmt.Sprintf("HTTP %s route not found", req.Method)
-			}
-
-			// Generate request ID if not exists
-			requestID := req.Header.Get("X-Request-ID")
-			if requestID == "" {
-				requestID = uuid.New().String()
-				c.Response().Header().Set("X-Request-ID", requestID)
-			}
-
 			// Create trace data
-			traceData := NewTraceData()
-			traceData.RequestID = requestID
-			traceData.Action = req.Method
-			traceData.Resource = route
-			traceData.Environment = config.Environment
-
-			// Enhanced attributes for better tracing
-			opts := []trace.SpanStartOption{
-				// Standard HTTP attributes
-				trace.WithAttributes(semconv.HTTPMethodKey.String(req.Method)),
-				trace.WithAttributes(semconv.HTTPTargetKey.String(req.URL.Path)),
-				trace.WithAttributes(semconv.HTTPRouteKey.String(route)),
-				trace.WithAttributes(semconv.HTTPHostKey.String(req.Host)),
-				trace.WithAttributes(semconv.HTTPSchemeKey.String(req.URL.Scheme)),
-				trace.WithAttributes(attribute.String("http.flavor", req.Proto)),
-
-				// Additional context attributes
-				trace.WithAttributes(attribute.String("http.client_ip", c.RealIP())),
-				trace.WithAttributes(attribute.String("http.user_agent", req.UserAgent())),
-				trace.WithAttributes(attribute.Int64("http.request_content_length", req.ContentLength)),
-				trace.WithAttributes(attribute.String("http.request_id", requestID)),
+			traceData := newRequestTraceData(c, config)
+			route := traceData.Resource
+
+			// Enhanced attributes for better tracing, keys are selected by the semconv mode
+			spanOpts := []trace.SpanStartOption{
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(mode.Convert([]attribute.KeyValue{
+					// Standard HTTP attributes
+					legacyHTTPMethodKey.String(req.Method),
+					legacyHTTPTargetKey.String(req.URL.Path),
+					semconv.HTTPRouteKey.String(route),
+					legacyHTTPHostKey.String(req.Host),
+					legacyHTTPSchemeKey.String(requestScheme(c)),
+					legacyHTTPFlavorKey.String(req.Proto),
+
+					// Request attributes kept for dashboards built on them, the same
+					// values are in TraceData
+					legacyHTTPClientIPKey.String(traceData.ClientIP),
+					legacyHTTPUserAgentKey.String(traceData.UserAgent),
+					legacyHTTPRequestLengthKey.Int64(req.ContentLength),
+					legacyHTTPRequestIDKey.String(traceData.RequestID),
+				})...),
 			}
 
 			spanName := route
@@ -70,11 +86,21 @@ func OtelMiddleware(config Config) echo.MiddlewareFunc {
 				spanName = fmt.Sprintf("HTTP %s", req.Method)
 			}
 
-			ctx, span := tracer.Start(ctx, spanName, opts...)
+			ctx, span := tracer.Start(ctx, spanName, spanOpts...)
 			defer span.End()
 
 			// Add trace data attributes
-			AddTraceAttributes(span, traceData)
+			addTraceAttributes(span, *traceData, classifier, mode)
+
+			// Identify the user before the handler so child spans and downstream
+			// services can see it
+			if options.userExtractor != nil {
+				userID, tenantID := options.userExtractor(c)
+				setUser(traceData, userID, tenantID)
+				if options.userBaggage {
+					ctx = contextWithUserBaggage(ctx, traceData.UserID, traceData.TenantID)
+				}
+			}
 
 			// Pass the span through the request context
 			c.SetRequest(req.WithContext(ctx))
@@ -82,27 +108,101 @@ func OtelMiddleware(config Config) echo.MiddlewareFunc {
 				Tracer:     tracer,
 				RequestCtx: ctx,
 			})
+			c.Set(TraceDataKey, traceData)
 
 			err := next(c)
+
+			completeRequestTraceData(c, traceData, err)
+			if options.userExtractor != nil && traceData.UserID == "" {
+				userID, tenantID := options.userExtractor(c)
+				setUser(traceData, userID, tenantID)
+			}
+
+			addTraceAttributes(span, *traceData, classifier, mode)
 			if err != nil {
-				traceData.Error = err
-				traceData.StatusCode = c.Response().Status
-				AddTraceAttributes(span, traceData)
-				span.RecordError(err)
-				return err
+				RecordError(span, err, WithStatusCode(traceData.StatusCode), WithErrorClassifier(classifier), withSemConvMode(mode))
+			} else {
+				span.SetAttributes(mode.Convert([]attribute.KeyValue{
+					legacyHTTPResponseLengthKey.Int64(traceData.ResponseSize),
+					legacyHTTPResponseContentTypeKey.String(c.Response().Header().Get(echo.HeaderContentType)),
+				})...)
 			}
+			metrics.recordRequest(ctx, *traceData)
+
+			return err
+		}
+	}
+}
 
-			// Update status code and response size in trace data
-			traceData.StatusCode = c.Response().Status
-			AddTraceAttributes(span, traceData)
+// newRequestTraceData creates the trace data of a request before it is handled
+func newRequestTraceData(c echo.Context, config Config) *TraceData {
+	req := c.Request()
+
+	traceData := NewTraceData()
+	traceData.RequestID = ensureRequestID(c)
+	traceData.ServiceName = config.ServiceName
+	traceData.Environment = config.Environment
+	traceData.Action = req.Method
+	traceData.Resource = requestRoute(c)
+	traceData.ClientIP = c.RealIP()
+	traceData.UserAgent = req.UserAgent()
+	if req.ContentLength > 0 {
+		traceData.RequestSize = req.ContentLength
+	}
+	return &traceData
+}
 
-			// Add response attributes
-			span.SetAttributes(
-				attribute.Int("http.response_content_length", int(c.Response().Size)),
-				attribute.String("http.response_content_type", c.Response().Header().Get(echo.HeaderContentType)),
-			)
+// completeRequestTraceData fills the fields known once the request is handled
+func completeRequestTraceData(c echo.Context, traceData *TraceData, err error) {
+	traceData.EndTime = time.Now()
+	traceData.Duration = float64(traceData.EndTime.Sub(traceData.StartTime).Microseconds()) / 1000
+	traceData.StatusCode = responseStatus(c, err)
+	traceData.ResponseSize = c.Response().Size
+	traceData.Error = err
+}
 
-			return nil
-		}
+// requestRoute returns the matched route template, or a placeholder when no route matched
+func requestRoute(c echo.Context) string {
+	route := c.Path()
+	if route == "" {
+		route = fmt.Sprintf("HTTP %s route not found", c.Request().Method)
+	}
+	return route
+}
+
+// requestScheme returns the scheme of the request; req.URL.Scheme is empty for server requests
+func requestScheme(c echo.Context) string {
+	if c.IsTLS() {
+		return "https"
+	}
+	return c.Scheme()
+}
+
+// ensureRequestID returns the request ID of the request, generating one and
+// setting it on the response when the client did not send one
+func ensureRequestID(c echo.Context) string {
+	requestID := c.Request().Header.Get(echo.HeaderXRequestID)
+	if requestID == "" {
+		requestID = c.Response().Header().Get(echo.HeaderXRequestID)
+	}
+	if requestID == "" {
+		requestID = uuid.New().String()
+		c.Response().Header().Set(echo.HeaderXRequestID, requestID)
+	}
+	return requestID
+}
+
+// responseStatus returns the status code the client receives. When the handler
+// returns an error the response is not committed yet, so the status comes from
+// the error the same way Echo's default error handler does.
+func responseStatus(c echo.Context, err error) int {
+	if err == nil || c.Response().Committed {
+		return c.Response().Status
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Code
 	}
+	return http.StatusInternalServerError
 }
@@ -0,0 +1,63 @@
+package otel_test
+
+import (
+	"errors"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tel "github.com/Doraverse-Workspace/open-observe/otel"
+	"github.com/Doraverse-Workspace/open-observe/oteltest"
+	"github.com/labstack/echo/v4"
+)
+
+// update rewrites the golden files under testdata: go test ./otel -update
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+func TestOtelMiddlewareSnapshot(t *testing.T) {
+	tests := []struct {
+		name    string
+		route   string
+		req     func() *http.Request
+		handler echo.HandlerFunc
+	}{
+		{
+			name:  "otel_middleware_ok",
+			route: "/users/:id",
+			req: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/users/42?fields=name", nil)
+				req.Header.Set(echo.HeaderXRequestID, "req-1")
+				req.Header.Set("User-Agent", "oteltest")
+				return req
+			},
+			handler: func(c echo.Context) error {
+				return c.String(http.StatusOK, c.Param("id"))
+			},
+		},
+		{
+			name:  "otel_middleware_error",
+			route: "/orders",
+			req: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{"sku":"a-1"}`))
+				req.Header.Set(echo.HeaderXRequestID, "req-2")
+				req.Header.Set("User-Agent", "oteltest")
+				return req
+			},
+			handler: func(c echo.Context) error {
+				return errors.New("order service unavailable")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := oteltest.New(t)
+
+			_, spans := r.ServeEcho(tel.Config{ServiceName: "users"}, tt.route, tt.handler, tt.req())
+
+			spans.MatchSnapshot(tt.name, oteltest.WithUpdate(*update))
+		})
+	}
+}
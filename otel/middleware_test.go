@@ -0,0 +1,80 @@
+package otel_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tel "github.com/Doraverse-Workspace/open-observe/otel"
+	"github.com/Doraverse-Workspace/open-observe/oteltest"
+	"github.com/labstack/echo/v4"
+)
+
+func TestOtelMiddlewareLegacyAttributes(t *testing.T) {
+	r := oteltest.New(t)
+	previous := tel.GetSemConvMode()
+	tel.SetSemConvMode(tel.SemConvLegacy)
+	t.Cleanup(func() { tel.SetSemConvMode(previous) })
+
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name":"ada"}`))
+	req.Header.Set(echo.HeaderXRequestID, "req-1")
+	req.Header.Set("User-Agent", "oteltest")
+	_, spans := r.ServeEcho(tel.Config{ServiceName: "users"}, "/users", func(c echo.Context) error {
+		return c.String(http.StatusCreated, "created")
+	}, req)
+
+	spans.Find("/users").
+		HasAttribute("http.client_ip", "192.0.2.1").
+		HasAttribute("http.user_agent", "oteltest").
+		HasAttribute("http.request_content_length", 14).
+		HasAttribute("http.request_id", "req-1").
+		HasAttribute("http.response_content_length", 7).
+		HasAttribute("client.ip", "192.0.2.1").
+		HasAttribute("user.agent", "oteltest").
+		HasAttribute("request.size", 14).
+		HasAttribute("response.size", 7).
+		HasAttributeKey("duration.ms")
+}
+
+func TestOtelMiddlewareDuplicateAttributes(t *testing.T) {
+	r := oteltest.New(t)
+	previous := tel.GetSemConvMode()
+	tel.SetSemConvMode(tel.SemConvDuplicate)
+	t.Cleanup(func() { tel.SetSemConvMode(previous) })
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set("User-Agent", "oteltest")
+	_, spans := r.ServeEcho(tel.Config{ServiceName: "users"}, "/users", func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	}, req)
+
+	spans.Find("/users").
+		HasAttribute("http.user_agent", "oteltest").
+		HasAttribute("user.agent", "oteltest").
+		HasAttribute("user_agent.original", "oteltest").
+		HasAttribute("client.address", "192.0.2.1").
+		HasAttribute("http.response.status_code", 200).
+		HasAttributeKey("duration.ms").
+		LacksAttribute("http.server.request.duration")
+}
+
+func TestOtelMiddlewareStableAttributes(t *testing.T) {
+	r := oteltest.New(t)
+	previous := tel.GetSemConvMode()
+	tel.SetSemConvMode(tel.SemConvStable)
+	t.Cleanup(func() { tel.SetSemConvMode(previous) })
+
+	_, spans := r.ServeEcho(tel.Config{ServiceName: "users"}, "/users", func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	}, httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	spans.Find("/users").
+		HasAttribute("http.request.method", "GET").
+		HasAttribute("http.response.status_code", 200).
+		HasAttribute("network.protocol.version", "1.1").
+		LacksAttribute("status.code").
+		LacksAttribute("http.method").
+		HasAttributeKey("duration.ms").
+		LacksAttribute("http.server.request.duration")
+}
@@ -11,16 +11,30 @@ import (
 // Endpoint: the endpoint of the collector http or grpc. Example: localhost:4318 or localhost:4317
 // IsSecure: whether the collector is secure true or false. If secure is true, the collector will use the https protocol.
 // BasicAuth: the basic auth of the collector. Example: base64(admin:password)
+// Organization: the OpenObserve organization, used in the HTTP URL paths and the gRPC organization header. Defaults to "default"
+// ErrorClassifier: decides which errors and status codes mark spans as failed. Defaults to DefaultErrorClassifier()
+// SemConvMode: emit legacy, current or both attribute key sets. Defaults to OTEL_SEMCONV_STABILITY_OPT_IN, then legacy
 type Config struct {
-	ServiceName string
-	Endpoint    string
-	IsSecure    bool
-	BasicAuth   string
-	Environment string
-	StreamName  string
+	ServiceName     string
+	Endpoint        string
+	IsSecure        bool
+	BasicAuth       string
+	Environment     string
+	StreamName      string
+	Organization    string
+	ErrorClassifier ErrorClassifier
+	SemConvMode     SemConvMode
 }
 
 type TraceContext struct {
 	Tracer     trace.Tracer
 	RequestCtx context.Context
 }
+
+// organization returns the configured organization or "default"
+func (c Config) organization() string {
+	if c.Organization == "" {
+		return "default"
+	}
+	return c.Organization
+}
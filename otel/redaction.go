@@ -0,0 +1,351 @@
+package otel
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// RedactedValue replaces values removed by a Scrubber
+const RedactedValue = "[REDACTED]"
+
+// Scrubber replaces the parts of string values matching Pattern with Replacement.
+// When Match is set, a match is only replaced if Match returns true.
+type Scrubber struct {
+	Name        string
+	Pattern     *regexp.Regexp
+	Replacement string
+	Match       func(s string) bool
+}
+
+var (
+	// EmailScrubber replaces email addresses
+	EmailScrubber = Scrubber{
+		Name:        "email",
+		Pattern:     regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+		Replacement: "[EMAIL]",
+	}
+
+	// CardNumberScrubber replaces payment card numbers, validated with the Luhn checksum
+	CardNumberScrubber = Scrubber{
+		Name:        "card",
+		Pattern:     regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`),
+		Replacement: "[CARD]",
+		Match:       luhnValid,
+	}
+
+	// JWTScrubber replaces JSON Web Tokens
+	JWTScrubber = Scrubber{
+		Name:        "jwt",
+		Pattern:     regexp.MustCompile(`eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+`),
+		Replacement: "[JWT]",
+	}
+
+	// TokenScrubber replaces bearer tokens and the values of secret query parameters
+	// such as token, access_token, api_key, password and secret
+	TokenScrubber = Scrubber{
+		Name:        "token",
+		Pattern:     regexp.MustCompile(`(?i)(bearer\s+|(?:access_token|refresh_token|id_token|token|api_key|apikey|password|passwd|secret)=)[^&\s"']+`),
+		Replacement: "${1}" + RedactedValue,
+	}
+)
+
+// RedactionRules configures a Redactor
+// DenyKeys: attributes removed from spans. A key ending with "*" matches a prefix, one starting with "*" a suffix
+// Scrubbers: applied to the span name and every string attribute value, event and link attribute and status description
+// TruncateIPKeys: attributes holding an IP, truncated to /24 (IPv4) or /48 (IPv6)
+// HashKeys: attributes replaced with a salted SHA-256, so values can still be grouped
+// HashSalt: the salt of HashKeys, keep it secret and stable
+type RedactionRules struct {
+	DenyKeys       []string
+	Scrubbers      []Scrubber
+	TruncateIPKeys []string
+	HashKeys       []string
+	HashSalt       string
+}
+
+// DefaultRedactionRules returns rules removing credentials, scrubbing emails,
+// card numbers and tokens, truncating client IPs and hashing user IDs with salt
+func DefaultRedactionRules(salt string) RedactionRules {
+	return RedactionRules{
+		DenyKeys: []string{
+			"http.request.header.authorization",
+			"http.request.header.cookie",
+			"http.response.header.set-cookie",
+			"password",
+			"*.password",
+		},
+		Scrubbers: []Scrubber{EmailScrubber, JWTScrubber, TokenScrubber, CardNumberScrubber},
+		TruncateIPKeys: []string{
+			string(legacyHTTPClientIPKey),
+			string(legacyClientIPKey),
+			"client.address",
+			"net.peer.ip",
+			"network.peer.address",
+		},
+		HashKeys: []string{"user.id", "enduser.id"},
+		HashSalt: salt,
+	}
+}
+
+// Redactor applies RedactionRules to attributes and spans
+type Redactor struct {
+	denyKeys     map[attribute.Key]bool
+	denyPrefixes []string
+	denySuffixes []string
+	scrubbers    []Scrubber
+	truncateIPs  map[attribute.Key]bool
+	hashKeys     map[attribute.Key]bool
+	salt         string
+}
+
+// NewRedactor compiles rules into a Redactor
+func NewRedactor(rules RedactionRules) *Redactor {
+	r := &Redactor{
+		denyKeys:    map[attribute.Key]bool{},
+		scrubbers:   rules.Scrubbers,
+		truncateIPs: map[attribute.Key]bool{},
+		hashKeys:    map[attribute.Key]bool{},
+		salt:        rules.HashSalt,
+	}
+	for _, key := range rules.DenyKeys {
+		if prefix, ok := strings.CutSuffix(key, "*"); ok {
+			r.denyPrefixes = append(r.denyPrefixes, prefix)
+			continue
+		}
+		if suffix, ok := strings.CutPrefix(key, "*"); ok {
+			r.denySuffixes = append(r.denySuffixes, suffix)
+			continue
+		}
+		r.denyKeys[attribute.Key(key)] = true
+	}
+	for _, key := range rules.TruncateIPKeys {
+		r.truncateIPs[attribute.Key(key)] = true
+	}
+	for _, key := range rules.HashKeys {
+		r.hashKeys[attribute.Key(key)] = true
+	}
+	return r
+}
+
+// Attributes returns the redacted attributes
+func (r *Redactor) Attributes(attrs []attribute.KeyValue) []attribute.KeyValue {
+	redacted := make([]attribute.KeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		if r.denied(attr.Key) {
+			continue
+		}
+
+		switch {
+		case r.hashKeys[attr.Key]:
+			attr = attr.Key.String(r.hash(attr.Value.Emit()))
+		case r.truncateIPs[attr.Key] && attr.Value.Type() == attribute.STRING:
+			attr = attr.Key.String(TruncateIP(attr.Value.AsString()))
+		case attr.Value.Type() == attribute.STRING:
+			attr = attr.Key.String(r.String(attr.Value.AsString()))
+		case attr.Value.Type() == attribute.STRINGSLICE:
+			values := attr.Value.AsStringSlice()
+			for i := range values {
+				values[i] = r.String(values[i])
+			}
+			attr = attr.Key.StringSlice(values)
+		}
+		redacted = append(redacted, attr)
+	}
+	return redacted
+}
+
+// String applies the scrubbers to s
+func (r *Redactor) String(s string) string {
+	for _, scrubber := range r.scrubbers {
+		if scrubber.Match == nil {
+			s = scrubber.Pattern.ReplaceAllString(s, scrubber.Replacement)
+			continue
+		}
+		s = scrubber.Pattern.ReplaceAllStringFunc(s, func(match string) string {
+			if !scrubber.Match(match) {
+				return match
+			}
+			return scrubber.Pattern.ReplaceAllString(match, scrubber.Replacement)
+		})
+	}
+	return s
+}
+
+// Span returns a copy of the span with a scrubbed name and redacted attributes,
+// event and link attributes and status description. The resource and the
+// instrumentation scope are set by the service, not by requests, and are kept.
+func (r *Redactor) Span(span sdktrace.ReadOnlySpan) sdktrace.ReadOnlySpan {
+	events := span.Events()
+	redactedEvents := make([]sdktrace.Event, len(events))
+	for i, event := range events {
+		event.Attributes = r.Attributes(event.Attributes)
+		redactedEvents[i] = event
+	}
+
+	links := span.Links()
+	redactedLinks := make([]sdktrace.Link, len(links))
+	for i, link := range links {
+		link.Attributes = r.Attributes(link.Attributes)
+		redactedLinks[i] = link
+	}
+
+	status := span.Status()
+	status.Description = r.String(status.Description)
+
+	return redactedSpan{
+		ReadOnlySpan: span,
+		name:         r.String(span.Name()),
+		attributes:   r.Attributes(span.Attributes()),
+		events:       redactedEvents,
+		links:        redactedLinks,
+		status:       status,
+	}
+}
+
+// denied reports whether the key is removed
+func (r *Redactor) denied(key attribute.Key) bool {
+	if r.denyKeys[key] {
+		return true
+	}
+	for _, prefix := range r.denyPrefixes {
+		if strings.HasPrefix(string(key), prefix) {
+			return true
+		}
+	}
+	for _, suffix := range r.denySuffixes {
+		if strings.HasSuffix(string(key), suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// hash returns the salted SHA-256 of value
+func (r *Redactor) hash(value string) string {
+	sum := sha256.Sum256([]byte(r.salt + value))
+	return hex.EncodeToString(sum[:])
+}
+
+// redactedSpan overrides the data of a span that may contain personal information
+type redactedSpan struct {
+	sdktrace.ReadOnlySpan
+	name       string
+	attributes []attribute.KeyValue
+	events     []sdktrace.Event
+	links      []sdktrace.Link
+	status     sdktrace.Status
+}
+
+func (s redactedSpan) Name() string                     { return s.name }
+func (s redactedSpan) Attributes() []attribute.KeyValue { return s.attributes }
+func (s redactedSpan) Events() []sdktrace.Event         { return s.events }
+func (s redactedSpan) Links() []sdktrace.Link           { return s.links }
+func (s redactedSpan) Status() sdktrace.Status          { return s.status }
+
+// RedactingExporter redacts spans before passing them to the wrapped exporter,
+// see Redactor.Span. The resource is exported unredacted.
+type RedactingExporter struct {
+	next     sdktrace.SpanExporter
+	redactor *Redactor
+}
+
+// NewRedactingExporter wraps next so that spans are redacted before they leave the process
+func NewRedactingExporter(next sdktrace.SpanExporter, rules RedactionRules) *RedactingExporter {
+	return &RedactingExporter{next: next, redactor: NewRedactor(rules)}
+}
+
+// ExportSpans redacts the spans and exports them
+func (e *RedactingExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	redacted := make([]sdktrace.ReadOnlySpan, len(spans))
+	for i, span := range spans {
+		redacted[i] = e.redactor.Span(span)
+	}
+	return e.next.ExportSpans(ctx, redacted)
+}
+
+// Shutdown shuts down the wrapped exporter
+func (e *RedactingExporter) Shutdown(ctx context.Context) error {
+	return e.next.Shutdown(ctx)
+}
+
+// RedactingSpanProcessor redacts ended spans before passing them to the wrapped
+// processor, e.g. a batch span processor
+type RedactingSpanProcessor struct {
+	next     sdktrace.SpanProcessor
+	redactor *Redactor
+}
+
+// NewRedactingSpanProcessor wraps next so that it only sees redacted spans
+func NewRedactingSpanProcessor(next sdktrace.SpanProcessor, rules RedactionRules) *RedactingSpanProcessor {
+	return &RedactingSpanProcessor{next: next, redactor: NewRedactor(rules)}
+}
+
+// OnStart passes the span to the wrapped processor
+func (p *RedactingSpanProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+// OnEnd redacts the span and passes it to the wrapped processor
+func (p *RedactingSpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	p.next.OnEnd(p.redactor.Span(s))
+}
+
+// Shutdown shuts down the wrapped processor
+func (p *RedactingSpanProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+// ForceFlush flushes the wrapped processor
+func (p *RedactingSpanProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+// TruncateIP zeroes the host part of an IP address: the last octet of IPv4
+// addresses and the last 80 bits of IPv6 addresses. Other values are redacted.
+func TruncateIP(value string) string {
+	ip := net.ParseIP(strings.TrimSpace(value))
+	if ip == nil {
+		if host, _, err := net.SplitHostPort(value); err == nil {
+			ip = net.ParseIP(host)
+		}
+	}
+	if ip == nil {
+		return RedactedValue
+	}
+
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.Mask(net.CIDRMask(24, 32)).String()
+	}
+	return ip.Mask(net.CIDRMask(48, 128)).String()
+}
+
+// luhnValid reports whether the digits of s pass the Luhn checksum
+func luhnValid(s string) bool {
+	sum := 0
+	double := false
+	digits := 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+		digits++
+	}
+	return digits >= 13 && sum%10 == 0
+}
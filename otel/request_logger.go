@@ -0,0 +1,85 @@
+package otel
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestLoggerMiddleware returns a middleware that emits one structured log record
+// per request through the OTLP log pipeline. It replaces Echo's middleware.Logger():
+// register it before OtelMiddleware so the record carries the request's trace and span
+// IDs and the TraceData completed by OtelMiddleware.
+func RequestLoggerMiddleware(config Config) echo.MiddlewareFunc {
+	if config.ServiceName == "" {
+		config.ServiceName = "default"
+	}
+	logger := global.GetLoggerProvider().Logger(config.ServiceName)
+	mode := config.SemConvMode.orGlobal()
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			traceData := newRequestTraceData(c, config)
+
+			err := next(c)
+
+			// Prefer the trace data of OtelMiddleware, it may hold the user ID and custom fields
+			if inner, ok := c.Get(TraceDataKey).(*TraceData); ok {
+				traceData = inner
+			}
+			if traceData.EndTime.IsZero() {
+				completeRequestTraceData(c, traceData, err)
+			}
+
+			req := c.Request()
+			severity := severityFromStatus(traceData.StatusCode)
+
+			var record log.Record
+			record.SetTimestamp(traceData.StartTime)
+			record.SetObservedTimestamp(time.Now())
+			record.SetSeverity(severity)
+			record.SetSeverityText(severity.String())
+			record.SetBody(log.StringValue(traceData.Action + " " + traceData.Resource))
+
+			attributes := append([]attribute.KeyValue{
+				legacyHTTPMethodKey.String(traceData.Action),
+				semconv.HTTPRouteKey.String(traceData.Resource),
+				legacyHTTPTargetKey.String(req.URL.Path),
+			}, traceData.Attributes()...)
+			for _, attr := range mode.Convert(attributes) {
+				record.AddAttributes(log.KeyValueFromAttribute(attr))
+			}
+
+			// OtelMiddleware replaces the request, so its context now holds the request span
+			ctx := req.Context()
+			if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.IsValid() {
+				record.AddAttributes(
+					log.String("trace_id", spanCtx.TraceID().String()),
+					log.String("span_id", spanCtx.SpanID().String()),
+				)
+			}
+
+			logger.Emit(context.WithoutCancel(ctx), record)
+
+			return err
+		}
+	}
+}
+
+// severityFromStatus maps a response status code to a log severity
+func severityFromStatus(status int) log.Severity {
+	switch {
+	case status >= 500:
+		return log.SeverityError
+	case status >= 400:
+		return log.SeverityWarn
+	default:
+		return log.SeverityInfo
+	}
+}
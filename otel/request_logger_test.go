@@ -0,0 +1,90 @@
+package otel_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	tel "github.com/Doraverse-Workspace/open-observe/otel"
+	"github.com/Doraverse-Workspace/open-observe/oteltest"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+	"go.opentelemetry.io/otel/log/noop"
+)
+
+func TestRequestLoggerMiddleware(t *testing.T) {
+	r := oteltest.New(t)
+	lp, exporter := newLoggerProvider(t)
+	global.SetLoggerProvider(lp)
+	t.Cleanup(func() {
+		global.SetLoggerProvider(noop.NewLoggerProvider())
+	})
+
+	config := tel.Config{ServiceName: "users"}
+	e := echo.New()
+	e.Use(tel.RequestLoggerMiddleware(config))
+	e.Use(tel.OtelMiddleware(config))
+	e.GET("/users/:id", func(c echo.Context) error {
+		if c.Param("id") == "0" {
+			return errors.New("user store unavailable")
+		}
+		return c.String(http.StatusOK, c.Param("id"))
+	})
+
+	for _, id := range []string{"42", "0"} {
+		req := httptest.NewRequest(http.MethodGet, "/users/"+id, nil)
+		req.Header.Set(echo.HeaderXRequestID, "req-"+id)
+		e.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	records := exporter.Records()
+	if len(records) != 2 {
+		t.Fatalf("%d records exported, want one per request", len(records))
+	}
+	spans := r.Spans().Named("/users/:id").All()
+	if len(spans) != 2 {
+		t.Fatalf("%d request spans, want 2", len(spans))
+	}
+
+	tests := []struct {
+		requestID string
+		status    int64
+		severity  log.Severity
+		target    string
+	}{
+		{requestID: "req-42", status: 200, severity: log.SeverityInfo, target: "/users/42"},
+		{requestID: "req-0", status: 500, severity: log.SeverityError, target: "/users/0"},
+	}
+	for i, tt := range tests {
+		record := records[i]
+		attrs := recordAttributes(record)
+		spanCtx := spans[i].SpanContext()
+
+		if record.Body().AsString() != "GET /users/:id" || record.Severity() != tt.severity {
+			t.Errorf("record %d = %q %v, want \"GET /users/:id\" %v", i, record.Body().AsString(), record.Severity(), tt.severity)
+		}
+		if got := attrs["http.route"].AsString(); got != "/users/:id" {
+			t.Errorf("record %d http.route = %q, want /users/:id", i, got)
+		}
+		if got := attrs["http.target"].AsString(); got != tt.target {
+			t.Errorf("record %d http.target = %q, want %s", i, got, tt.target)
+		}
+		if got := attrs["status.code"].AsInt64(); got != tt.status {
+			t.Errorf("record %d status.code = %d, want %d", i, got, tt.status)
+		}
+		if got := attrs["request.id"].AsString(); got != tt.requestID {
+			t.Errorf("record %d request.id = %q, want %s", i, got, tt.requestID)
+		}
+		if _, ok := attrs["duration.ms"]; !ok {
+			t.Errorf("record %d has no duration.ms", i)
+		}
+		if got := attrs["trace_id"].AsString(); got != spanCtx.TraceID().String() {
+			t.Errorf("record %d trace_id = %q, want the request span's %s", i, got, spanCtx.TraceID())
+		}
+		if got := attrs["span_id"].AsString(); got != spanCtx.SpanID().String() {
+			t.Errorf("record %d span_id = %q, want the request span's %s", i, got, spanCtx.SpanID())
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package otel
+
+import (
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+)
+
+// SemConvMode selects which attribute keys are emitted while dashboards migrate
+// from the legacy keys to the current OpenTelemetry semantic conventions
+type SemConvMode int
+
+const (
+	// SemConvDefault reads OTEL_SEMCONV_STABILITY_OPT_IN ("http" or "http/dup")
+	// and falls back to SemConvLegacy
+	SemConvDefault SemConvMode = iota
+	// SemConvLegacy emits the legacy keys, e.g. http.method, status.code, environment
+	SemConvLegacy
+	// SemConvStable emits the current keys, e.g. http.request.method,
+	// http.response.status_code, deployment.environment.name
+	SemConvStable
+	// SemConvDuplicate emits both key sets
+	SemConvDuplicate
+)
+
+// Legacy attribute keys, as emitted before the semantic convention modes, that
+// have a replacement in the current semantic conventions
+const (
+	legacyHTTPMethodKey              = attribute.Key("http.method")
+	legacyHTTPTargetKey              = attribute.Key("http.target")
+	legacyHTTPHostKey                = attribute.Key("http.host")
+	legacyHTTPSchemeKey              = attribute.Key("http.scheme")
+	legacyHTTPFlavorKey              = attribute.Key("http.flavor")
+	legacyHTTPClientIPKey            = attribute.Key("http.client_ip")
+	legacyHTTPUserAgentKey           = attribute.Key("http.user_agent")
+	legacyHTTPRequestLengthKey       = attribute.Key("http.request_content_length")
+	legacyHTTPResponseLengthKey      = attribute.Key("http.response_content_length")
+	legacyStatusCodeKey              = attribute.Key("status.code")
+	legacyClientIPKey                = attribute.Key("client.ip")
+	legacyUserAgentKey               = attribute.Key("user.agent")
+	legacyRequestSizeKey             = attribute.Key("request.size")
+	legacyResponseSizeKey            = attribute.Key("response.size")
+	legacyEnvironmentKey             = attribute.Key("environment")
+	legacyHTTPResponseContentTypeKey = attribute.Key("http.response_content_type")
+	stableHTTPResponseContentTypeKey = attribute.Key("http.response.header.content-type")
+)
+
+// stableKeys maps legacy keys to their current semantic convention key
+var stableKeys = map[attribute.Key]attribute.Key{
+	legacyHTTPMethodKey:              semconv.HTTPRequestMethodKey,
+	legacyHTTPTargetKey:              semconv.URLPathKey,
+	legacyHTTPHostKey:                semconv.ServerAddressKey,
+	legacyHTTPSchemeKey:              semconv.URLSchemeKey,
+	legacyHTTPFlavorKey:              semconv.NetworkProtocolVersionKey,
+	legacyHTTPClientIPKey:            semconv.ClientAddressKey,
+	legacyHTTPUserAgentKey:           semconv.UserAgentOriginalKey,
+	legacyHTTPRequestLengthKey:       semconv.HTTPRequestBodySizeKey,
+	legacyHTTPResponseLengthKey:      semconv.HTTPResponseBodySizeKey,
+	legacyStatusCodeKey:              semconv.HTTPResponseStatusCodeKey,
+	legacyClientIPKey:                semconv.ClientAddressKey,
+	legacyUserAgentKey:               semconv.UserAgentOriginalKey,
+	legacyRequestSizeKey:             semconv.HTTPRequestBodySizeKey,
+	legacyResponseSizeKey:            semconv.HTTPResponseBodySizeKey,
+	legacyEnvironmentKey:             semconv.DeploymentEnvironmentNameKey,
+	legacyHTTPResponseContentTypeKey: stableHTTPResponseContentTypeKey,
+}
+
+type semConvHolder struct {
+	mode SemConvMode
+}
+
+var globalSemConvMode atomic.Value
+
+func init() {
+	globalSemConvMode.Store(semConvHolder{mode: SemConvDefault.resolve()})
+}
+
+// SetSemConvMode sets the mode used by AddTraceAttributes, RecordError and the
+// instrumentation of this module. InitTracerHTTP and InitTracerGRPC call it with
+// Config.SemConvMode when set.
+func SetSemConvMode(mode SemConvMode) {
+	globalSemConvMode.Store(semConvHolder{mode: mode.resolve()})
+}
+
+// GetSemConvMode returns the mode set with SetSemConvMode
+func GetSemConvMode() SemConvMode {
+	return globalSemConvMode.Load().(semConvHolder).mode
+}
+
+// resolve replaces SemConvDefault with the mode from the environment
+func (m SemConvMode) resolve() SemConvMode {
+	if m != SemConvDefault {
+		return m
+	}
+
+	for _, value := range strings.Split(os.Getenv("OTEL_SEMCONV_STABILITY_OPT_IN"), ",") {
+		switch strings.TrimSpace(value) {
+		case "http/dup":
+			return SemConvDuplicate
+		case "http":
+			return SemConvStable
+		}
+	}
+	return SemConvLegacy
+}
+
+// Convert rewrites attributes built with legacy keys for the mode. Attributes
+// without a replacement are kept unchanged. SemConvDefault uses the global mode.
+func (m SemConvMode) Convert(attrs []attribute.KeyValue) []attribute.KeyValue {
+	m = m.orGlobal()
+	if m == SemConvLegacy {
+		return attrs
+	}
+
+	converted := make([]attribute.KeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		stableKey, ok := stableKeys[attr.Key]
+		if !ok {
+			converted = append(converted, attr)
+			continue
+		}
+		if m == SemConvDuplicate {
+			converted = append(converted, attr)
+		}
+		converted = append(converted, stableAttribute(stableKey, attr.Value))
+	}
+	return converted
+}
+
+// stableAttribute converts legacy values whose format changed along with the key
+func stableAttribute(key attribute.Key, value attribute.Value) attribute.KeyValue {
+	if key == semconv.NetworkProtocolVersionKey {
+		// http.flavor was "HTTP/1.1", network.protocol.version is "1.1"
+		return key.String(strings.TrimPrefix(value.AsString(), "HTTP/"))
+	}
+	return attribute.KeyValue{Key: key, Value: value}
+}
+
+// newResource returns the resource describing the service, with the environment
+// key selected by the semantic convention mode
+func newResource(config Config) *resource.Resource {
+	attrs := []attribute.KeyValue{
+		semconv.ServiceName(config.ServiceName),
+		semconv.ServiceVersion("0.0.1"),
+	}
+	if config.Environment != "" {
+		attrs = append(attrs, config.SemConvMode.Convert([]attribute.KeyValue{
+			legacyEnvironmentKey.String(config.Environment),
+		})...)
+	}
+
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...)
+}
+
+// orGlobal returns m, or the global mode when m is SemConvDefault
+func (m SemConvMode) orGlobal() SemConvMode {
+	if m == SemConvDefault {
+		return GetSemConvMode()
+	}
+	return m
+}
@@ -0,0 +1,223 @@
+package otel
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SlogHandlerOptions configures the handler returned by NewSlogHandler
+// Level: the minimum level to handle, defaults to slog.LevelInfo
+// LoggerProvider: the provider used to emit OTLP records, defaults to the global logger provider
+// Tee: when set, every record is also written as JSON to this writer (e.g. os.Stdout)
+type SlogHandlerOptions struct {
+	Level          slog.Leveler
+	LoggerProvider log.LoggerProvider
+	Tee            io.Writer
+}
+
+// SlogHandler is a slog.Handler that emits records through the OTLP log pipeline
+// and correlates them with the active span of the context.
+type SlogHandler struct {
+	logger      log.Logger
+	level       slog.Leveler
+	tee         slog.Handler
+	serviceName string
+	environment string
+	attrs       []log.KeyValue
+	groups      []string
+}
+
+// NewSlogHandler creates a slog.Handler that adds trace_id, span_id, service name
+// and environment to every record.
+func NewSlogHandler(config Config, opts SlogHandlerOptions) *SlogHandler {
+	if config.ServiceName == "" {
+		config.ServiceName = "default"
+	}
+
+	provider := opts.LoggerProvider
+	if provider == nil {
+		provider = global.GetLoggerProvider()
+	}
+
+	level := opts.Level
+	if level == nil {
+		level = slog.LevelInfo
+	}
+
+	h := &SlogHandler{
+		logger:      provider.Logger(config.ServiceName),
+		level:       level,
+		serviceName: config.ServiceName,
+		environment: config.Environment,
+	}
+
+	if opts.Tee != nil {
+		h.tee = slog.NewJSONHandler(opts.Tee, &slog.HandlerOptions{Level: level})
+	}
+
+	return h
+}
+
+// Enabled reports whether the handler handles records at the given level
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle emits the record to the OTLP logger and, if configured, to the tee handler
+func (h *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	correlation := h.correlationAttrs(ctx)
+
+	var otelRecord log.Record
+	timestamp := record.Time
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	otelRecord.SetTimestamp(timestamp)
+	otelRecord.SetObservedTimestamp(time.Now())
+	otelRecord.SetBody(log.StringValue(record.Message))
+	otelRecord.SetSeverity(SeverityFromLevel(record.Level))
+	otelRecord.SetSeverityText(record.Level.String())
+
+	otelRecord.AddAttributes(h.attrs...)
+	record.Attrs(func(attr slog.Attr) bool {
+		if kv, ok := h.convertAttr(attr); ok {
+			otelRecord.AddAttributes(kv)
+		}
+		return true
+	})
+	for _, attr := range correlation {
+		otelRecord.AddAttributes(log.String(attr.Key, attr.Value.String()))
+	}
+
+	h.logger.Emit(ctx, otelRecord)
+
+	if h.tee != nil {
+		teeRecord := record.Clone()
+		teeRecord.AddAttrs(correlation...)
+		if err := h.tee.Handle(ctx, teeRecord); err != nil {
+			return fmt.Errorf("failed to write log record: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// WithAttrs returns a handler that adds attrs to every record
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append([]log.KeyValue{}, h.attrs...)
+	for _, attr := range attrs {
+		if kv, ok := h.convertAttr(attr); ok {
+			clone.attrs = append(clone.attrs, kv)
+		}
+	}
+	if h.tee != nil {
+		clone.tee = h.tee.WithAttrs(attrs)
+	}
+	return &clone
+}
+
+// WithGroup returns a handler that qualifies the keys of later attributes with name
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.groups = append(append([]string{}, h.groups...), name)
+	if h.tee != nil {
+		clone.tee = h.tee.WithGroup(name)
+	}
+	return &clone
+}
+
+// correlationAttrs returns the trace and service attributes added to every record
+func (h *SlogHandler) correlationAttrs(ctx context.Context) []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("service.name", h.serviceName),
+	}
+	if h.environment != "" {
+		attrs = append(attrs, slog.String("environment", h.environment))
+	}
+
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if spanCtx.IsValid() {
+		attrs = append(attrs,
+			slog.String("trace_id", spanCtx.TraceID().String()),
+			slog.String("span_id", spanCtx.SpanID().String()),
+		)
+	}
+
+	return attrs
+}
+
+// convertAttr converts a slog attribute to a log attribute, prefixing the key
+// with the open groups
+func (h *SlogHandler) convertAttr(attr slog.Attr) (log.KeyValue, bool) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return log.KeyValue{}, false
+	}
+
+	key := attr.Key
+	for i := len(h.groups) - 1; i >= 0; i-- {
+		key = h.groups[i] + "." + key
+	}
+
+	return log.KeyValue{Key: key, Value: convertSlogValue(attr.Value)}, true
+}
+
+// convertSlogValue converts a resolved slog value to a log value
+func convertSlogValue(v slog.Value) log.Value {
+	switch v.Kind() {
+	case slog.KindString:
+		return log.StringValue(v.String())
+	case slog.KindInt64:
+		return log.Int64Value(v.Int64())
+	case slog.KindUint64:
+		return log.Int64Value(int64(v.Uint64()))
+	case slog.KindFloat64:
+		return log.Float64Value(v.Float64())
+	case slog.KindBool:
+		return log.BoolValue(v.Bool())
+	case slog.KindDuration:
+		return log.Int64Value(v.Duration().Nanoseconds())
+	case slog.KindTime:
+		return log.StringValue(v.Time().Format(time.RFC3339Nano))
+	case slog.KindGroup:
+		group := v.Group()
+		kvs := make([]log.KeyValue, 0, len(group))
+		for _, attr := range group {
+			attr.Value = attr.Value.Resolve()
+			kvs = append(kvs, log.KeyValue{Key: attr.Key, Value: convertSlogValue(attr.Value)})
+		}
+		return log.MapValue(kvs...)
+	case slog.KindLogValuer:
+		return convertSlogValue(v.Resolve())
+	default:
+		if err, ok := v.Any().(error); ok {
+			return log.StringValue(err.Error())
+		}
+		return log.StringValue(fmt.Sprint(v.Any()))
+	}
+}
+
+// SeverityFromLevel maps a slog level to an OTLP severity number.
+// DEBUG, INFO, WARN and ERROR map to the first severity of the matching range,
+// levels in between map to the next numbers of the range (e.g. INFO+2 is INFO3).
+func SeverityFromLevel(level slog.Level) log.Severity {
+	severity := log.Severity(int(level) + int(log.SeverityInfo1))
+	if severity < log.SeverityTrace1 {
+		return log.SeverityTrace1
+	}
+	if severity > log.SeverityFatal4 {
+		return log.SeverityFatal4
+	}
+	return severity
+}
@@ -0,0 +1,161 @@
+package otel_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"testing"
+
+	tel "github.com/Doraverse-Workspace/open-observe/otel"
+	"github.com/Doraverse-Workspace/open-observe/oteltest"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// logExporter keeps the exported log records in memory
+type logExporter struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (e *logExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, record := range records {
+		e.records = append(e.records, record.Clone())
+	}
+	return nil
+}
+
+func (e *logExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func (e *logExporter) ForceFlush(context.Context) error {
+	return nil
+}
+
+// Records returns the exported records
+func (e *logExporter) Records() []sdklog.Record {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]sdklog.Record{}, e.records...)
+}
+
+// newLoggerProvider returns a logger provider exporting synchronously to a new logExporter
+func newLoggerProvider(t *testing.T) (*sdklog.LoggerProvider, *logExporter) {
+	t.Helper()
+
+	exporter := &logExporter{}
+	lp := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exporter)))
+	t.Cleanup(func() {
+		_ = lp.Shutdown(context.Background())
+	})
+	return lp, exporter
+}
+
+// recordAttributes returns the attributes of record by key
+func recordAttributes(record sdklog.Record) map[string]log.Value {
+	attrs := map[string]log.Value{}
+	record.WalkAttributes(func(kv log.KeyValue) bool {
+		attrs[kv.Key] = kv.Value
+		return true
+	})
+	return attrs
+}
+
+func TestSeverityFromLevel(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  log.Severity
+	}{
+		{level: slog.LevelDebug, want: log.SeverityDebug1},
+		{level: slog.LevelInfo, want: log.SeverityInfo1},
+		{level: slog.LevelInfo + 2, want: log.SeverityInfo3},
+		{level: slog.LevelWarn, want: log.SeverityWarn1},
+		{level: slog.LevelError, want: log.SeverityError1},
+		{level: slog.LevelError + 4, want: log.SeverityFatal1},
+		{level: slog.LevelDebug - 10, want: log.SeverityTrace1},
+		{level: slog.LevelError + 20, want: log.SeverityFatal4},
+	}
+
+	for _, tt := range tests {
+		if got := tel.SeverityFromLevel(tt.level); got != tt.want {
+			t.Errorf("SeverityFromLevel(%v) = %v, want %v", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestSlogHandlerCorrelation(t *testing.T) {
+	lp, exporter := newLoggerProvider(t)
+	r := oteltest.NewLocal(t)
+	var tee bytes.Buffer
+	logger := slog.New(tel.NewSlogHandler(tel.Config{ServiceName: "orders", Environment: "test"}, tel.SlogHandlerOptions{
+		LoggerProvider: lp,
+		Tee:            &tee,
+	})).With(slog.String("component", "checkout"))
+
+	ctx, span := r.Tracer("test").Start(context.Background(), "checkout")
+	logger.WarnContext(ctx, "payment retried", slog.Int("attempt", 2), slog.Group("order", slog.String("id", "o-1")))
+	logger.DebugContext(ctx, "below the level")
+	span.End()
+
+	records := exporter.Records()
+	if len(records) != 1 {
+		t.Fatalf("%d records exported, want 1", len(records))
+	}
+	record := records[0]
+	if record.Body().AsString() != "payment retried" || record.Severity() != log.SeverityWarn1 || record.SeverityText() != "WARN" {
+		t.Errorf("record = %q %v %q, want \"payment retried\" WARN1 WARN", record.Body().AsString(), record.Severity(), record.SeverityText())
+	}
+
+	spanCtx := span.SpanContext()
+	attrs := recordAttributes(record)
+	want := map[string]string{
+		"trace_id":     spanCtx.TraceID().String(),
+		"span_id":      spanCtx.SpanID().String(),
+		"service.name": "orders",
+		"environment":  "test",
+		"component":    "checkout",
+	}
+	for key, value := range want {
+		if got := attrs[key].AsString(); got != value {
+			t.Errorf("attribute %s = %q, want %q", key, got, value)
+		}
+	}
+	if got := attrs["attempt"].AsInt64(); got != 2 {
+		t.Errorf("attribute attempt = %d, want 2", got)
+	}
+	if got := attrs["order"].AsMap(); len(got) != 1 || got[0].Key != "id" || got[0].Value.AsString() != "o-1" {
+		t.Errorf("attribute order = %v, want {id: o-1}", got)
+	}
+
+	var teed map[string]any
+	if err := json.Unmarshal(tee.Bytes(), &teed); err != nil {
+		t.Fatalf("tee output %q is not one JSON record: %v", tee.String(), err)
+	}
+	if teed["msg"] != "payment retried" || teed["trace_id"] != spanCtx.TraceID().String() || teed["span_id"] != spanCtx.SpanID().String() || teed["component"] != "checkout" {
+		t.Errorf("tee record = %v, want the message with the trace and span IDs", teed)
+	}
+}
+
+func TestSlogHandlerWithoutSpan(t *testing.T) {
+	lp, exporter := newLoggerProvider(t)
+	logger := slog.New(tel.NewSlogHandler(tel.Config{}, tel.SlogHandlerOptions{LoggerProvider: lp}))
+
+	logger.Info("started")
+
+	records := exporter.Records()
+	if len(records) != 1 {
+		t.Fatalf("%d records exported, want 1", len(records))
+	}
+	attrs := recordAttributes(records[0])
+	if _, ok := attrs["trace_id"]; ok {
+		t.Errorf("trace_id = %v, want none outside a span", attrs["trace_id"])
+	}
+	if got := attrs["service.name"].AsString(); got != "default" {
+		t.Errorf("service.name = %q, want default", got)
+	}
+}
@@ -0,0 +1,394 @@
+package otel
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// defaultMaxRouteExporters bounds the exporters cached by a RoutingExporter
+const defaultMaxRouteExporters = 64
+
+// TenantRoute is the OpenObserve organization and stream the spans of a tenant are sent to
+type TenantRoute struct {
+	Organization string
+	StreamName   string
+}
+
+// String returns the route as organization/stream
+func (r TenantRoute) String() string {
+	return r.Organization + "/" + r.StreamName
+}
+
+// RouteStats counts the exports of a route
+type RouteStats struct {
+	Spans      int64
+	Batches    int64
+	Errors     int64
+	LastError  string
+	LastExport time.Time
+}
+
+// ExporterFactory creates the exporter of a route
+type ExporterFactory func(route TenantRoute) (sdktrace.SpanExporter, error)
+
+// RoutingConfig configures a RoutingExporter
+// TenantAttribute: the span attribute holding the tenant. Defaults to "tenant.id"
+// Route: maps a tenant to its route. Defaults to the tenant's stream in DefaultRoute's organization
+// DefaultRoute: the route of spans without tenant
+// NewExporter: creates the exporter of a route. Defaults to TenantHTTPExporterFactory(Config{}), the local OpenObserve
+// MaxExporters: the number of route exporters kept open, the least recently used is shut down. Defaults to 64
+type RoutingConfig struct {
+	TenantAttribute attribute.Key
+	Route           func(tenantID string) TenantRoute
+	DefaultRoute    TenantRoute
+	NewExporter     ExporterFactory
+	MaxExporters    int
+}
+
+// RoutingExporter is a span exporter that sends each span to the OpenObserve
+// stream, and optionally organization, of its tenant
+type RoutingExporter struct {
+	config RoutingConfig
+
+	mu        sync.Mutex
+	exporters map[TenantRoute]*list.Element
+	lru       *list.List
+	stats     map[TenantRoute]*RouteStats
+	evictions int64
+}
+
+type routeExporter struct {
+	route    TenantRoute
+	exporter sdktrace.SpanExporter
+}
+
+// NewRoutingExporter creates a RoutingExporter
+func NewRoutingExporter(config RoutingConfig) *RoutingExporter {
+	if config.TenantAttribute == "" {
+		config.TenantAttribute = TenantIDBaggageKey
+	}
+	if config.DefaultRoute.Organization == "" {
+		config.DefaultRoute.Organization = "default"
+	}
+	if config.DefaultRoute.StreamName == "" {
+		config.DefaultRoute.StreamName = "default"
+	}
+	if config.Route == nil {
+		organization := config.DefaultRoute.Organization
+		config.Route = func(tenantID string) TenantRoute {
+			return TenantRoute{Organization: organization, StreamName: StreamNameFromTenant(tenantID)}
+		}
+	}
+	if config.NewExporter == nil {
+		config.NewExporter = TenantHTTPExporterFactory(Config{})
+	}
+	if config.MaxExporters <= 0 {
+		config.MaxExporters = defaultMaxRouteExporters
+	}
+
+	return &RoutingExporter{
+		config:    config,
+		exporters: map[TenantRoute]*list.Element{},
+		lru:       list.New(),
+		stats:     map[TenantRoute]*RouteStats{},
+	}
+}
+
+// ExportSpans groups the spans by route and exports each group with the route's exporter
+func (e *RoutingExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	groups := map[TenantRoute][]sdktrace.ReadOnlySpan{}
+	var order []TenantRoute
+	for _, span := range spans {
+		route := e.routeOf(span)
+		if _, ok := groups[route]; !ok {
+			order = append(order, route)
+		}
+		groups[route] = append(groups[route], span)
+	}
+
+	var errs []error
+	for _, route := range order {
+		exporter, err := e.exporter(ctx, route)
+		if err == nil {
+			err = exporter.ExportSpans(ctx, groups[route])
+		}
+		e.record(route, len(groups[route]), err)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("export to %s: %w", route, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Shutdown shuts down every cached exporter
+func (e *RoutingExporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	var exporters []sdktrace.SpanExporter
+	for el := e.lru.Front(); el != nil; el = el.Next() {
+		exporters = append(exporters, el.Value.(*routeExporter).exporter)
+	}
+	e.lru.Init()
+	e.exporters = map[TenantRoute]*list.Element{}
+	e.mu.Unlock()
+
+	var errs []error
+	for _, exporter := range exporters {
+		if err := exporter.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Stats returns the stats of every route exported to, including the routes whose
+// exporter was evicted. There is one entry per route, the number of tenants bounds it.
+func (e *RoutingExporter) Stats() map[TenantRoute]RouteStats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	stats := make(map[TenantRoute]RouteStats, len(e.stats))
+	for route, s := range e.stats {
+		stats[route] = *s
+	}
+	return stats
+}
+
+// Evictions returns the number of exporters shut down to respect MaxExporters
+func (e *RoutingExporter) Evictions() int64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.evictions
+}
+
+// routeOf returns the route of a span from its tenant attribute
+func (e *RoutingExporter) routeOf(span sdktrace.ReadOnlySpan) TenantRoute {
+	for _, attr := range span.Attributes() {
+		if attr.Key == e.config.TenantAttribute && attr.Value.AsString() != "" {
+			return e.config.Route(attr.Value.AsString())
+		}
+	}
+	return e.config.DefaultRoute
+}
+
+// exporter returns the cached exporter of a route, creating it when missing.
+// Exporters are created and shut down outside the lock since both can do
+// network I/O, and exports to the other routes must not wait for them.
+func (e *RoutingExporter) exporter(ctx context.Context, route TenantRoute) (sdktrace.SpanExporter, error) {
+	if exporter, ok := e.cachedExporter(route); ok {
+		return exporter, nil
+	}
+
+	created, err := e.config.NewExporter(route)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exporter: %w", err)
+	}
+
+	e.mu.Lock()
+	var evicted []*routeExporter
+	exporter := created
+	if el, ok := e.exporters[route]; ok {
+		// Another export created the exporter of the route meanwhile
+		e.lru.MoveToFront(el)
+		exporter = el.Value.(*routeExporter).exporter
+	} else {
+		e.exporters[route] = e.lru.PushFront(&routeExporter{route: route, exporter: created})
+		for e.lru.Len() > e.config.MaxExporters {
+			// The stats of the route are kept, its next export creates a new exporter
+			oldest := e.lru.Back()
+			e.lru.Remove(oldest)
+			evicted = append(evicted, oldest.Value.(*routeExporter))
+			delete(e.exporters, oldest.Value.(*routeExporter).route)
+			e.evictions++
+		}
+	}
+	e.mu.Unlock()
+
+	if exporter != created {
+		evicted = append(evicted, &routeExporter{route: route, exporter: created})
+	}
+	for _, old := range evicted {
+		if err := old.exporter.Shutdown(ctx); err != nil {
+			otel.Handle(fmt.Errorf("failed to shut down exporter of %s: %w", old.route, err))
+		}
+	}
+
+	return exporter, nil
+}
+
+// cachedExporter returns the cached exporter of a route and marks it as recently used
+func (e *RoutingExporter) cachedExporter(route TenantRoute) (sdktrace.SpanExporter, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	el, ok := e.exporters[route]
+	if !ok {
+		return nil, false
+	}
+	e.lru.MoveToFront(el)
+	return el.Value.(*routeExporter).exporter, true
+}
+
+// record updates the stats of a route after an export
+func (e *RoutingExporter) record(route TenantRoute, spans int, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	stats, ok := e.stats[route]
+	if !ok {
+		stats = &RouteStats{}
+		e.stats[route] = stats
+	}
+	stats.Batches++
+	stats.LastExport = time.Now()
+	if err != nil {
+		stats.Errors++
+		stats.LastError = err.Error()
+		return
+	}
+	stats.Spans += int64(spans)
+}
+
+// StreamNameFromTenant converts a tenant ID to a valid OpenObserve stream name:
+// lower case letters, digits and underscores
+func StreamNameFromTenant(tenantID string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(tenantID) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteByte('_')
+		}
+	}
+	return sb.String()
+}
+
+// TenantHTTPExporterFactory creates OTLP/HTTP exporters sending to the route's
+// organization and stream, with the endpoint and credentials of config
+func TenantHTTPExporterFactory(config Config) ExporterFactory {
+	return func(route TenantRoute) (sdktrace.SpanExporter, error) {
+		endpoint := "127.0.0.1:5081"
+		if config.Endpoint != "" {
+			endpoint = config.Endpoint
+		}
+
+		opts := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(endpoint),
+			otlptracehttp.WithURLPath("/api/" + route.Organization + "/v1/traces"),
+			otlptracehttp.WithHeaders(map[string]string{
+				"Authorization": "Basic " + config.BasicAuth,
+				"stream-name":   route.StreamName,
+			}),
+		}
+		if !config.IsSecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+
+		return otlptracehttp.New(context.Background(), opts...)
+	}
+}
+
+// TenantGRPCExporterFactory creates OTLP/gRPC exporters sending to the route's
+// organization and stream, with the endpoint and credentials of config
+func TenantGRPCExporterFactory(config Config) ExporterFactory {
+	return func(route TenantRoute) (sdktrace.SpanExporter, error) {
+		endpoint := "127.0.0.1:5081"
+		if config.Endpoint != "" {
+			endpoint = config.Endpoint
+		}
+
+		opts := []otlptracegrpc.Option{
+			otlptracegrpc.WithEndpoint(endpoint),
+			otlptracegrpc.WithHeaders(map[string]string{
+				"Authorization": "Basic " + config.BasicAuth,
+				"organization":  route.Organization,
+				"stream-name":   route.StreamName,
+			}),
+		}
+		if !config.IsSecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+
+		return otlptracegrpc.New(context.Background(), opts...)
+	}
+}
+
+// BaggageSpanProcessor copies baggage entries of the parent context to span
+// attributes when spans start, so child spans carry the tenant set by WithUserBaggage
+type BaggageSpanProcessor struct {
+	keys []string
+}
+
+// NewBaggageSpanProcessor creates a processor copying the given baggage keys
+func NewBaggageSpanProcessor(keys ...string) *BaggageSpanProcessor {
+	return &BaggageSpanProcessor{keys: keys}
+}
+
+// OnStart copies the baggage entries to the span
+func (p *BaggageSpanProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	bag := baggage.FromContext(parent)
+	for _, key := range p.keys {
+		if value := bag.Member(key).Value(); value != "" {
+			s.SetAttributes(attribute.String(key, value))
+		}
+	}
+}
+
+// OnEnd does nothing
+func (p *BaggageSpanProcessor) OnEnd(sdktrace.ReadOnlySpan) {}
+
+// Shutdown does nothing
+func (p *BaggageSpanProcessor) Shutdown(context.Context) error { return nil }
+
+// ForceFlush does nothing
+func (p *BaggageSpanProcessor) ForceFlush(context.Context) error { return nil }
+
+// InitTracerHTTPWithTenantRouting initializes a tracer provider that sends spans
+// to per-tenant streams over OTLP/HTTP. Spans without tenant go to the organization
+// and stream of config. Use it with OtelMiddleware's WithUserExtractor and
+// WithUserBaggage so that every span of a request carries the tenant.
+func InitTracerHTTPWithTenantRouting(config Config, routing RoutingConfig) (*sdktrace.TracerProvider, *RoutingExporter) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if routing.DefaultRoute.Organization == "" {
+		routing.DefaultRoute.Organization = config.organization()
+	}
+	if routing.DefaultRoute.StreamName == "" {
+		routing.DefaultRoute.StreamName = config.StreamName
+	}
+	if routing.NewExporter == nil {
+		routing.NewExporter = TenantHTTPExporterFactory(config)
+	}
+	exporter := NewRoutingExporter(routing)
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithResource(newResource(config)),
+		sdktrace.WithSpanProcessor(NewBaggageSpanProcessor(string(exporter.config.TenantAttribute))),
+		sdktrace.WithBatcher(exporter),
+	)
+	otel.SetTracerProvider(tp)
+	if config.ErrorClassifier != nil {
+		SetErrorClassifier(config.ErrorClassifier)
+	}
+	if config.SemConvMode != SemConvDefault {
+		SetSemConvMode(config.SemConvMode)
+	}
+
+	return tp, exporter
+}
@@ -0,0 +1,157 @@
+package otel_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	tel "github.com/Doraverse-Workspace/open-observe/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// blockingExporter counts exported spans, its Shutdown waits for release
+type blockingExporter struct {
+	mu       sync.Mutex
+	spans    int
+	shutdown chan struct{}
+	release  chan struct{}
+}
+
+func newBlockingExporter() *blockingExporter {
+	return &blockingExporter{shutdown: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (e *blockingExporter) ExportSpans(_ context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans += len(spans)
+	return nil
+}
+
+func (e *blockingExporter) Shutdown(context.Context) error {
+	close(e.shutdown)
+	<-e.release
+	return nil
+}
+
+func tenantSpans(tenants ...string) []sdktrace.ReadOnlySpan {
+	stubs := make(tracetest.SpanStubs, len(tenants))
+	for i, tenant := range tenants {
+		stubs[i] = tracetest.SpanStub{
+			Name:       "op",
+			Attributes: []attribute.KeyValue{attribute.String(tel.TenantIDBaggageKey, tenant)},
+		}
+	}
+	return stubs.Snapshots()
+}
+
+func TestRoutingExporterDefaultFactory(t *testing.T) {
+	exporter := tel.NewRoutingExporter(tel.RoutingConfig{})
+
+	// Nothing listens on the default endpoint: the export fails instead of panicking
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if err := exporter.ExportSpans(ctx, tenantSpans("acme")); err == nil {
+		t.Error("ExportSpans to the default endpoint succeeded, want a connection error")
+	}
+	_ = exporter.Shutdown(context.Background())
+}
+
+func TestRoutingExporterEvictsOutsideLock(t *testing.T) {
+	exporters := map[string]*blockingExporter{}
+	var mu sync.Mutex
+	exporter := tel.NewRoutingExporter(tel.RoutingConfig{
+		MaxExporters: 1,
+		NewExporter: func(route tel.TenantRoute) (sdktrace.SpanExporter, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			exporters[route.StreamName] = newBlockingExporter()
+			return exporters[route.StreamName], nil
+		},
+	})
+
+	if err := exporter.ExportSpans(context.Background(), tenantSpans("acme", "acme")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Routing a second tenant evicts acme, whose Shutdown blocks until released
+	done := make(chan error)
+	go func() {
+		done <- exporter.ExportSpans(context.Background(), tenantSpans("globex"))
+	}()
+	mu.Lock()
+	acme := exporters["acme"]
+	mu.Unlock()
+	select {
+	case <-acme.shutdown:
+	case <-time.After(time.Second):
+		t.Fatal("acme exporter was not shut down")
+	}
+
+	statsDone := make(chan map[tel.TenantRoute]tel.RouteStats)
+	go func() {
+		statsDone <- exporter.Stats()
+	}()
+	select {
+	case <-statsDone:
+	case <-time.After(time.Second):
+		t.Fatal("Stats blocked while an evicted exporter was shutting down")
+	}
+
+	close(acme.release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if acme.spans != 2 {
+		t.Errorf("acme exported %d spans, want 2", acme.spans)
+	}
+	if got := exporter.Evictions(); got != 1 {
+		t.Errorf("Evictions() = %d, want 1", got)
+	}
+	stats := exporter.Stats()[tel.TenantRoute{Organization: "default", StreamName: "globex"}]
+	if stats.Spans != 1 || stats.Batches != 1 {
+		t.Errorf("globex stats = %+v, want 1 span in 1 batch", stats)
+	}
+}
+
+func TestRoutingExporterStatsSurviveEviction(t *testing.T) {
+	exporter := tel.NewRoutingExporter(tel.RoutingConfig{
+		MaxExporters: 1,
+		NewExporter: func(tel.TenantRoute) (sdktrace.SpanExporter, error) {
+			return tracetest.NewInMemoryExporter(), nil
+		},
+	})
+	t.Cleanup(func() {
+		_ = exporter.Shutdown(context.Background())
+	})
+
+	// Each tenant evicts the exporter of the previous one
+	for _, spans := range [][]sdktrace.ReadOnlySpan{
+		tenantSpans("acme", "acme"),
+		tenantSpans("globex"),
+		tenantSpans("acme"),
+		tenantSpans("initech"),
+	} {
+		if err := exporter.ExportSpans(context.Background(), spans); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := exporter.Evictions(); n != 3 {
+		t.Errorf("Evictions() = %d, want 3", n)
+	}
+	stats := exporter.Stats()
+	want := map[string][2]int64{"acme": {3, 2}, "globex": {1, 1}, "initech": {1, 1}}
+	if len(stats) != len(want) {
+		t.Errorf("Stats() has %d routes, want %d: %v", len(stats), len(want), stats)
+	}
+	for tenant, counts := range want {
+		got := stats[tel.TenantRoute{Organization: "default", StreamName: tenant}]
+		if got.Spans != counts[0] || got.Batches != counts[1] {
+			t.Errorf("stats of %s = %d spans in %d batches, want %d in %d", tenant, got.Spans, got.Batches, counts[0], counts[1])
+		}
+	}
+}
@@ -10,72 +10,74 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
-// TraceData represents the structure for additional tracing information
+// TraceData represents the structure for additional tracing information.
+// The json tags are the attribute keys, so the same struct can be written to logs.
+// They keep the keys spans always had (client.ip, user.agent, request.size,
+// response.size, duration.ms) so existing dashboards keep working. Empty fields
+// are omitted from the span.
 type TraceData struct {
-	UserID       string
-	RequestID    string
-	ServiceName  string
-	Environment  string
-	Version      string
-	Region       string
-	Action       string
-	Resource     string
-	StatusCode   int
-	Error        error
-	ClientIP     string    `json:"client_ip,omitempty"`
-	UserAgent    string    `json:"user_agent,omitempty"`
-	RequestSize  int64     `json:"request_size,omitempty"`
-	ResponseSize int64     `json:"response_size,omitempty"`
-	Duration     float64   `json:"duration,omitempty"`
+	UserID       string    `json:"user.id,omitempty"`
+	TenantID     string    `json:"tenant.id,omitempty"`
+	RequestID    string    `json:"request.id,omitempty"`
+	ServiceName  string    `json:"service.name,omitempty"`
+	Environment  string    `json:"environment,omitempty"`
+	Version      string    `json:"version,omitempty"`
+	Region       string    `json:"region,omitempty"`
+	Action       string    `json:"action,omitempty"`
+	Resource     string    `json:"resource,omitempty"`
+	StatusCode   int       `json:"status.code,omitempty"`
+	Error        error     `json:"-"`
+	ClientIP     string    `json:"client.ip,omitempty"`
+	UserAgent    string    `json:"user.agent,omitempty"`
+	RequestSize  int64     `json:"request.size,omitempty"`
+	ResponseSize int64     `json:"response.size,omitempty"`
+	Duration     float64   `json:"duration.ms,omitempty"`
 	StartTime    time.Time `json:"start_time,omitempty"`
 	EndTime      time.Time `json:"end_time,omitempty"`
-}
-
-// AddTraceAttributes adds custom attributes to a span
-func AddTraceAttributes(span trace.Span, data TraceData) {
-	// Add custom attributes to the span
-	attributes := []attribute.KeyValue{
-		attribute.String("user.id", data.UserID),
-		attribute.String("request.id", data.RequestID),
-		attribute.String("service.name", data.ServiceName),
-		attribute.String("environment", data.Environment),
-		attribute.String("version", data.Version),
-		attribute.String("region", data.Region),
-		attribute.String("action", data.Action),
-		attribute.String("resource", data.Resource),
-		attribute.Int("status.code", data.StatusCode),
-		attribute.String("client.ip", data.ClientIP),
-		attribute.String("user.agent", data.UserAgent),
-		attribute.Int64("request.size", data.RequestSize),
-		attribute.Int64("response.size", data.ResponseSize),
-		attribute.Float64("duration.ms", data.Duration),
-	}
 
-	if !data.StartTime.IsZero() {
-		attributes = append(attributes, attribute.String("start_time", data.StartTime.Format(time.RFC3339)))
-	}
+	// Custom holds service-specific fields. Keys are used as-is; struct and map
+	// values are flattened with the key as prefix, see StructAttributes.
+	Custom map[string]any `json:"custom,omitempty"`
+}
 
-	if !data.EndTime.IsZero() {
-		attributes = append(attributes, attribute.String("end_time", data.EndTime.Format(time.RFC3339)))
-	}
+// Attributes returns the non-empty fields of the trace data as span attributes
+func (data TraceData) Attributes() []attribute.KeyValue {
+	custom := data.Custom
+	data.Custom = nil
 
+	attributes := StructAttributes(data)
 	if data.Error != nil {
 		attributes = append(attributes, attribute.String("error.message", data.Error.Error()))
 	}
+	return append(attributes, StructAttributes(custom)...)
+}
 
-	if data.StatusCode != 0 {
-		if data.StatusCode == http.StatusOK {
+// AddTraceAttributes adds custom attributes to a span and sets the span status
+// according to the registered ErrorClassifier. Keys follow the registered SemConvMode.
+func AddTraceAttributes(span trace.Span, data TraceData) {
+	addTraceAttributes(span, data, GetErrorClassifier(), GetSemConvMode())
+}
+
+func addTraceAttributes(span trace.Span, data TraceData, classifier ErrorClassifier, mode SemConvMode) {
+	attributes := data.Attributes()
+
+	if data.StatusCode != 0 || data.Error != nil {
+		switch classifyWithDefaults(classifier, data.Error, data.StatusCode) {
+		case ErrorClassSuccess:
 			span.SetStatus(codes.Ok, "Success")
-		} else {
+		case ErrorClassError:
 			if data.Error != nil {
 				span.SetStatus(codes.Error, data.Error.Error())
 			} else {
-				span.SetStatus(codes.Error, "Unknown error")
+				span.SetStatus(codes.Error, http.StatusText(data.StatusCode))
 			}
+		case ErrorClassClient:
+			// Client faults leave the status unset, the request was served correctly
+			attributes = append(attributes, attribute.String("error.kind", ErrorKindClient.String()))
 		}
 	}
 
-	span.SetAttributes(attributes...)
+	span.SetAttributes(mode.Convert(attributes)...)
 }
 
 // NewTraceData creates a new TraceData instance with default values
@@ -94,15 +96,10 @@ func NewTracer(serviceName string) trace.Tracer {
 	return otel.Tracer(serviceName)
 }
 
-// TraceError traces an error and records it in the span
+// TraceError traces an error and records it in the span.
+// Use RecordError to classify the error or set its status code.
 func TraceError(span trace.Span, err error) {
-	AddTraceAttributes(span, TraceData{
-		StatusCode: 400,
-		Error:      err,
-		EndTime:    time.Now(),
-	})
-	span.RecordError(err)
-	span.SetStatus(codes.Error, err.Error())
+	RecordError(span, err, WithStackSkip(1))
 }
 
 // TraceSuccess traces a success and records it in the span
@@ -0,0 +1,186 @@
+package otel
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+const (
+	// UserIDBaggageKey is the baggage key of the user ID set by WithUserBaggage
+	UserIDBaggageKey = "user.id"
+	// TenantIDBaggageKey is the baggage key of the tenant ID set by WithUserBaggage
+	TenantIDBaggageKey = "tenant.id"
+)
+
+// UserExtractor returns the user and tenant of a request, empty when unknown
+type UserExtractor func(c echo.Context) (userID, tenantID string)
+
+// WithUserExtractor sets the function that identifies the user of the request.
+// It runs before the handler, and again after it when the user was still unknown,
+// so values set by authentication middleware registered after OtelMiddleware are
+// picked up as well.
+func WithUserExtractor(extractor UserExtractor) MiddlewareOption {
+	return func(o *middlewareOptions) {
+		o.userExtractor = extractor
+	}
+}
+
+// WithUserIDExtractor sets a function that only reads the user ID of the request,
+// see WithUserExtractor.
+func WithUserIDExtractor(extractor func(c echo.Context) string) MiddlewareOption {
+	return WithUserExtractor(func(c echo.Context) (string, string) {
+		return extractor(c), ""
+	})
+}
+
+// WithUserBaggage adds the extracted user and tenant IDs to the baggage of the
+// request context, so they are propagated to downstream services. Baggage can
+// only be set before the handler runs: the user must be known when
+// OtelMiddleware runs, e.g. with JWTExtractor. When authentication middleware
+// registered after OtelMiddleware identifies the user, use UserBaggageMiddleware
+// after it instead.
+func WithUserBaggage() MiddlewareOption {
+	return func(o *middlewareOptions) {
+		o.userBaggage = true
+	}
+}
+
+// UserBaggageMiddleware identifies the user with extractor and adds the user and
+// tenant IDs to the baggage of the request context and to the request's
+// TraceData. Register it after the authentication middleware, which usually runs
+// after OtelMiddleware:
+//
+//	e.Use(otel.OtelMiddleware(config))
+//	e.Use(authMiddleware)
+//	e.Use(otel.UserBaggageMiddleware(otel.ContextExtractor("user_id", "tenant_id")))
+func UserBaggageMiddleware(extractor UserExtractor) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userID, tenantID := extractor(c)
+			if userID == "" && tenantID == "" {
+				return next(c)
+			}
+
+			if traceData, ok := c.Get(TraceDataKey).(*TraceData); ok {
+				setUser(traceData, userID, tenantID)
+			}
+
+			req := c.Request()
+			ctx := contextWithUserBaggage(req.Context(), userID, tenantID)
+			c.SetRequest(req.WithContext(ctx))
+			if tracerCtx, ok := c.Get(TraceContextKey).(TraceContext); ok {
+				tracerCtx.RequestCtx = ctx
+				c.Set(TraceContextKey, tracerCtx)
+			}
+			return next(c)
+		}
+	}
+}
+
+// JWTExtractor reads the user ID from the sub claim of the JWT sent in header,
+// with or without a "Bearer " prefix, and the tenant ID from tenantClaim when set.
+// The token is parsed WITHOUT verifying its signature: only use it behind
+// middleware that authenticates the request.
+func JWTExtractor(header, tenantClaim string) UserExtractor {
+	return func(c echo.Context) (string, string) {
+		token := strings.TrimSpace(c.Request().Header.Get(header))
+		if len(token) > 7 && strings.EqualFold(token[:7], "bearer ") {
+			token = strings.TrimSpace(token[7:])
+		}
+		if token == "" {
+			return "", ""
+		}
+
+		claims, err := parseJWTClaims(token)
+		if err != nil {
+			return "", ""
+		}
+		return claimString(claims["sub"]), claimString(claims[tenantClaim])
+	}
+}
+
+// ContextExtractor reads the user and tenant IDs stored in the Echo context by
+// authentication middleware (c.Set(userKey, ...)). An empty key is skipped.
+func ContextExtractor(userKey, tenantKey string) UserExtractor {
+	return func(c echo.Context) (string, string) {
+		var userID, tenantID string
+		if userKey != "" {
+			userID = claimString(c.Get(userKey))
+		}
+		if tenantKey != "" {
+			tenantID = claimString(c.Get(tenantKey))
+		}
+		return userID, tenantID
+	}
+}
+
+// parseJWTClaims decodes the payload of a JWT without verifying it
+func parseJWTClaims(token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid JWT: expected 3 parts, got %d", len(parts))
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT payload: %w", err)
+	}
+
+	claims := map[string]any{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("invalid JWT claims: %w", err)
+	}
+	return claims, nil
+}
+
+// claimString converts a claim or context value to a string
+func claimString(v any) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case fmt.Stringer:
+		return value.String()
+	case float64:
+		// JSON numbers, e.g. numeric user IDs
+		return fmt.Sprintf("%.0f", value)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// setUser sets the user and tenant IDs of data that are known, an extractor that
+// only finds one of them keeps the other
+func setUser(data *TraceData, userID, tenantID string) {
+	if userID != "" {
+		data.UserID = userID
+	}
+	if tenantID != "" {
+		data.TenantID = tenantID
+	}
+}
+
+// contextWithUserBaggage adds the user and tenant IDs to the baggage of ctx
+func contextWithUserBaggage(ctx context.Context, userID, tenantID string) context.Context {
+	bag := baggage.FromContext(ctx)
+	for key, value := range map[string]string{UserIDBaggageKey: userID, TenantIDBaggageKey: tenantID} {
+		if value == "" {
+			continue
+		}
+		member, err := baggage.NewMemberRaw(key, value)
+		if err != nil {
+			continue
+		}
+		if next, err := bag.SetMember(member); err == nil {
+			bag = next
+		}
+	}
+	return baggage.ContextWithBaggage(ctx, bag)
+}
@@ -0,0 +1,61 @@
+package otel_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	tel "github.com/Doraverse-Workspace/open-observe/otel"
+	"github.com/Doraverse-Workspace/open-observe/oteltest"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+func TestUserBaggageMiddlewareAfterAuth(t *testing.T) {
+	r := oteltest.New(t)
+
+	auth := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user_id", "u-42")
+			c.Set("tenant_id", "acme")
+			return next(c)
+		}
+	}
+	var userBaggage, tenantBaggage string
+	handler := func(c echo.Context) error {
+		bag := baggage.FromContext(c.Request().Context())
+		userBaggage = bag.Member(tel.UserIDBaggageKey).Value()
+		tenantBaggage = bag.Member(tel.TenantIDBaggageKey).Value()
+		return c.NoContent(http.StatusNoContent)
+	}
+	chain := auth(tel.UserBaggageMiddleware(tel.ContextExtractor("user_id", "tenant_id"))(handler))
+
+	_, spans := r.ServeEcho(tel.Config{ServiceName: "users"}, "/me", chain, httptest.NewRequest(http.MethodGet, "/me", nil))
+
+	if userBaggage != "u-42" || tenantBaggage != "acme" {
+		t.Errorf("baggage user.id = %q, tenant.id = %q, want u-42 and acme", userBaggage, tenantBaggage)
+	}
+	spans.Find("/me").
+		HasAttribute("user.id", "u-42").
+		HasAttribute("tenant.id", "acme")
+}
+
+func TestUserBaggageMiddlewareKeepsTenant(t *testing.T) {
+	r := oteltest.New(t)
+
+	tenantOnly := tel.WithUserExtractor(func(echo.Context) (string, string) {
+		return "", "acme"
+	})
+	userOnly := tel.UserBaggageMiddleware(func(echo.Context) (string, string) {
+		return "u-42", ""
+	})
+	handler := userOnly(func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	_, spans := r.ServeEcho(tel.Config{ServiceName: "users"}, "/me", handler, httptest.NewRequest(http.MethodGet, "/me", nil), tenantOnly)
+
+	spans.Find("/me").
+		HasAttribute("user.id", "u-42").
+		HasAttribute("tenant.id", "acme")
+}
@@ -0,0 +1,227 @@
+package oteltest
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SpanAssert holds fluent assertions on a recorded span. Failed assertions are
+// reported with tb.Errorf so that one run shows all of them, except Child and
+// Parent which stop the test when the span is missing.
+type SpanAssert struct {
+	tb    testing.TB
+	span  sdktrace.ReadOnlySpan
+	spans Spans
+}
+
+// Span returns the asserted span
+func (a *SpanAssert) Span() sdktrace.ReadOnlySpan {
+	return a.span
+}
+
+// HasAttribute asserts the span has the attribute key set to value. Go ints and
+// floats match the int64 and float64 attribute values, e.g. HasAttribute("http.status_code", 200).
+func (a *SpanAssert) HasAttribute(key string, value any) *SpanAssert {
+	a.tb.Helper()
+
+	got, ok := a.attribute(key)
+	if !ok {
+		a.tb.Errorf("oteltest: span %q has no attribute %q, want %v", a.span.Name(), key, value)
+		return a
+	}
+	want := attributeValue(value)
+	if got.Type() != want.Type() || !reflect.DeepEqual(got.AsInterface(), want.AsInterface()) {
+		a.tb.Errorf("oteltest: span %q attribute %q = %s (%s), want %s (%s)",
+			a.span.Name(), key, got.Emit(), got.Type(), want.Emit(), want.Type())
+	}
+	return a
+}
+
+// HasAttributeKey asserts the span has the attribute key, whatever its value
+func (a *SpanAssert) HasAttributeKey(key string) *SpanAssert {
+	a.tb.Helper()
+
+	if _, ok := a.attribute(key); !ok {
+		a.tb.Errorf("oteltest: span %q has no attribute %q", a.span.Name(), key)
+	}
+	return a
+}
+
+// LacksAttribute asserts the span does not have the attribute key
+func (a *SpanAssert) LacksAttribute(key string) *SpanAssert {
+	a.tb.Helper()
+
+	if got, ok := a.attribute(key); ok {
+		a.tb.Errorf("oteltest: span %q has attribute %q = %s, want none", a.span.Name(), key, got.Emit())
+	}
+	return a
+}
+
+// HasStatus asserts the span status code, and its description when given
+func (a *SpanAssert) HasStatus(code codes.Code, description ...string) *SpanAssert {
+	a.tb.Helper()
+
+	status := a.span.Status()
+	if status.Code != code {
+		a.tb.Errorf("oteltest: span %q status = %s, want %s", a.span.Name(), status.Code, code)
+	}
+	if len(description) > 0 && status.Description != description[0] {
+		a.tb.Errorf("oteltest: span %q status description = %q, want %q", a.span.Name(), status.Description, description[0])
+	}
+	return a
+}
+
+// HasKind asserts the span kind
+func (a *SpanAssert) HasKind(kind trace.SpanKind) *SpanAssert {
+	a.tb.Helper()
+
+	if a.span.SpanKind() != kind {
+		a.tb.Errorf("oteltest: span %q kind = %s, want %s", a.span.Name(), a.span.SpanKind(), kind)
+	}
+	return a
+}
+
+// HasEvent asserts the span has an event named name carrying attrs
+func (a *SpanAssert) HasEvent(name string, attrs ...attribute.KeyValue) *SpanAssert {
+	a.tb.Helper()
+
+	for _, event := range a.span.Events() {
+		if event.Name == name && containsAll(event.Attributes, attrs) {
+			return a
+		}
+	}
+	if len(attrs) > 0 {
+		a.tb.Errorf("oteltest: span %q has no event %q with attributes %v", a.span.Name(), name, attrs)
+	} else {
+		a.tb.Errorf("oteltest: span %q has no event %q", a.span.Name(), name)
+	}
+	return a
+}
+
+// IsRoot asserts the span has no parent among the recorded spans
+func (a *SpanAssert) IsRoot() *SpanAssert {
+	a.tb.Helper()
+
+	if parent := a.spans.byID(a.span.Parent().SpanID()); parent != nil {
+		a.tb.Errorf("oteltest: span %q has parent %q, want none", a.span.Name(), parent.Name())
+	}
+	return a
+}
+
+// HasParent asserts the parent of the span is named name
+func (a *SpanAssert) HasParent(name string) *SpanAssert {
+	a.tb.Helper()
+
+	parent := a.spans.byID(a.span.Parent().SpanID())
+	switch {
+	case parent == nil:
+		a.tb.Errorf("oteltest: span %q has no recorded parent, want %q", a.span.Name(), name)
+	case parent.Name() != name:
+		a.tb.Errorf("oteltest: span %q has parent %q, want %q", a.span.Name(), parent.Name(), name)
+	}
+	return a
+}
+
+// HasChild asserts the span has a direct child named name
+func (a *SpanAssert) HasChild(name string) *SpanAssert {
+	a.tb.Helper()
+
+	if a.spans.ChildrenOf(a.span).Named(name).Len() == 0 {
+		a.tb.Errorf("oteltest: span %q has no child %q, children: %v", a.span.Name(), name, a.spans.ChildrenOf(a.span).Names())
+	}
+	return a
+}
+
+// Child returns the assertions of the first direct child named name, stopping
+// the test when there is none
+func (a *SpanAssert) Child(name string) *SpanAssert {
+	a.tb.Helper()
+
+	children := a.spans.ChildrenOf(a.span).Named(name)
+	if children.Len() == 0 {
+		a.tb.Fatalf("oteltest: span %q has no child %q, children: %v", a.span.Name(), name, a.spans.ChildrenOf(a.span).Names())
+	}
+	return &SpanAssert{tb: a.tb, span: children.spans[0], spans: a.spans}
+}
+
+// Parent returns the assertions of the parent span, stopping the test when it
+// was not recorded
+func (a *SpanAssert) Parent() *SpanAssert {
+	a.tb.Helper()
+
+	parent := a.spans.byID(a.span.Parent().SpanID())
+	if parent == nil {
+		a.tb.Fatalf("oteltest: span %q has no recorded parent", a.span.Name())
+	}
+	return &SpanAssert{tb: a.tb, span: parent, spans: a.spans}
+}
+
+// attribute returns the value of the attribute key, the last one wins like in
+// the exported span
+func (a *SpanAssert) attribute(key string) (attribute.Value, bool) {
+	var value attribute.Value
+	found := false
+	for _, kv := range a.span.Attributes() {
+		if string(kv.Key) == key {
+			value, found = kv.Value, true
+		}
+	}
+	return value, found
+}
+
+// containsAll reports whether attrs has every attribute of want
+func containsAll(attrs, want []attribute.KeyValue) bool {
+	for _, w := range want {
+		found := false
+		for _, kv := range attrs {
+			if kv.Key == w.Key && kv.Value == w.Value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// attributeValue converts a Go value to the attribute value it is recorded as
+func attributeValue(v any) attribute.Value {
+	switch v := v.(type) {
+	case attribute.Value:
+		return v
+	case string:
+		return attribute.StringValue(v)
+	case bool:
+		return attribute.BoolValue(v)
+	case int:
+		return attribute.IntValue(v)
+	case int32:
+		return attribute.Int64Value(int64(v))
+	case int64:
+		return attribute.Int64Value(v)
+	case float32:
+		return attribute.Float64Value(float64(v))
+	case float64:
+		return attribute.Float64Value(v)
+	case []string:
+		return attribute.StringSliceValue(v)
+	case []bool:
+		return attribute.BoolSliceValue(v)
+	case []int:
+		return attribute.IntSliceValue(v)
+	case []int64:
+		return attribute.Int64SliceValue(v)
+	case []float64:
+		return attribute.Float64SliceValue(v)
+	default:
+		return attribute.StringValue(fmt.Sprint(v))
+	}
+}
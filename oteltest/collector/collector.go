@@ -0,0 +1,317 @@
+package collector
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
+)
+
+// Signal is the kind of telemetry of an export request
+type Signal string
+
+const (
+	SignalTraces  Signal = "traces"
+	SignalMetrics Signal = "metrics"
+	SignalLogs    Signal = "logs"
+)
+
+// Request is an export request received by the collector
+// Protocol: "http" or "grpc"
+// Path: the URL path for HTTP, e.g. /api/default/v1/traces, the full method for gRPC
+// Header: the HTTP headers or the gRPC metadata, e.g. Header.Get("stream-name")
+// ContentType: application/x-protobuf, application/json or application/grpc
+// Compression: the Content-Encoding or grpc-encoding, e.g. gzip
+// Status: the HTTP status returned, gRPC errors are mapped back to the HTTP status they were scripted with
+// Items: the number of spans, metrics or log records in the request, 0 when it failed
+type Request struct {
+	Protocol    string
+	Signal      Signal
+	Path        string
+	Header      http.Header
+	ContentType string
+	Compression string
+	Status      int
+	Items       int
+	Received    time.Time
+}
+
+// Response scripts the answer to an export request
+// Status: the HTTP status, mapped to a gRPC code for gRPC requests. 0 means 200
+// Delay: wait before answering, the request context still applies
+// RetryAfter: sent as Retry-After for HTTP, or as RetryInfo for gRPC
+type Response struct {
+	Status     int
+	Delay      time.Duration
+	RetryAfter time.Duration
+}
+
+// ok reports whether the response accepts the request
+func (r Response) ok() bool {
+	return r.Status == 0 || r.Status == http.StatusOK
+}
+
+// Collector is a fake OpenObserve/OTLP collector receiving traces, metrics and
+// logs over HTTP and gRPC. Payloads of accepted requests are decoded and kept
+// for inspection.
+type Collector struct {
+	tb           testing.TB
+	httpServer   *httptest.Server
+	grpcServer   *grpc.Server
+	grpcListener net.Listener
+
+	mu              sync.Mutex
+	requests        []Request
+	resourceSpans   []*tracepb.ResourceSpans
+	resourceMetrics []*metricspb.ResourceMetrics
+	resourceLogs    []*logspb.ResourceLogs
+	queue           []Response
+	respond         func(Request) Response
+}
+
+// New starts a collector on local ports, it is stopped when the test ends.
+// Point a Config at it with Endpoint: c.HTTPEndpoint() or c.GRPCEndpoint().
+func New(tb testing.TB) *Collector {
+	tb.Helper()
+
+	c := &Collector{tb: tb}
+	c.httpServer = httptest.NewServer(http.HandlerFunc(c.serveHTTP))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		c.httpServer.Close()
+		tb.Fatalf("collector: failed to listen for gRPC: %v", err)
+	}
+	c.grpcListener = listener
+	c.grpcServer = newGRPCServer(c)
+	go func() {
+		_ = c.grpcServer.Serve(listener)
+	}()
+
+	tb.Cleanup(c.Close)
+	return c
+}
+
+// HTTPEndpoint returns the host:port of the OTLP/HTTP receiver
+func (c *Collector) HTTPEndpoint() string {
+	return strings.TrimPrefix(c.httpServer.URL, "http://")
+}
+
+// GRPCEndpoint returns the host:port of the OTLP/gRPC receiver
+func (c *Collector) GRPCEndpoint() string {
+	return c.grpcListener.Addr().String()
+}
+
+// Close stops both receivers
+func (c *Collector) Close() {
+	c.grpcServer.Stop()
+	c.httpServer.Close()
+}
+
+// Enqueue scripts the answers to the next requests, one response per request in
+// order. Requests are accepted once the queue is empty, unless RespondWith is set.
+func (c *Collector) Enqueue(responses ...Response) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.queue = append(c.queue, responses...)
+}
+
+// RespondWith scripts the answer to the requests not answered by Enqueue
+func (c *Collector) RespondWith(respond func(Request) Response) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.respond = respond
+}
+
+// Reset drops the recorded requests and payloads and the scripted responses
+func (c *Collector) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = nil
+	c.resourceSpans = nil
+	c.resourceMetrics = nil
+	c.resourceLogs = nil
+	c.queue = nil
+	c.respond = nil
+}
+
+// Requests returns the requests received so far, failed ones included
+func (c *Collector) Requests() []Request {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Request(nil), c.requests...)
+}
+
+// WaitForRequests waits until n requests were received and returns them,
+// failing the test after timeout. Exporters batch in the background, flushing
+// the provider (tp.ForceFlush) is usually faster.
+func (c *Collector) WaitForRequests(n int, timeout time.Duration) []Request {
+	c.tb.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		requests := c.Requests()
+		if len(requests) >= n {
+			return requests
+		}
+		if time.Now().After(deadline) {
+			c.tb.Fatalf("collector: received %d requests after %s, want %d", len(requests), timeout, n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// ResourceSpans returns the spans of the accepted requests as received
+func (c *Collector) ResourceSpans() []*tracepb.ResourceSpans {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*tracepb.ResourceSpans(nil), c.resourceSpans...)
+}
+
+// Spans returns the spans of the accepted requests
+func (c *Collector) Spans() []*tracepb.Span {
+	var spans []*tracepb.Span
+	for _, rs := range c.ResourceSpans() {
+		for _, ss := range rs.GetScopeSpans() {
+			spans = append(spans, ss.GetSpans()...)
+		}
+	}
+	return spans
+}
+
+// SpanNames returns the names of the spans of the accepted requests
+func (c *Collector) SpanNames() []string {
+	var names []string
+	for _, span := range c.Spans() {
+		names = append(names, span.GetName())
+	}
+	return names
+}
+
+// Span returns the first span named name, nil when none was received
+func (c *Collector) Span(name string) *tracepb.Span {
+	for _, span := range c.Spans() {
+		if span.GetName() == name {
+			return span
+		}
+	}
+	return nil
+}
+
+// ResourceMetrics returns the metrics of the accepted requests as received
+func (c *Collector) ResourceMetrics() []*metricspb.ResourceMetrics {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*metricspb.ResourceMetrics(nil), c.resourceMetrics...)
+}
+
+// Metrics returns the metrics of the accepted requests
+func (c *Collector) Metrics() []*metricspb.Metric {
+	var metrics []*metricspb.Metric
+	for _, rm := range c.ResourceMetrics() {
+		for _, sm := range rm.GetScopeMetrics() {
+			metrics = append(metrics, sm.GetMetrics()...)
+		}
+	}
+	return metrics
+}
+
+// Metric returns the first metric named name, nil when none was received
+func (c *Collector) Metric(name string) *metricspb.Metric {
+	for _, metric := range c.Metrics() {
+		if metric.GetName() == name {
+			return metric
+		}
+	}
+	return nil
+}
+
+// ResourceLogs returns the log records of the accepted requests as received
+func (c *Collector) ResourceLogs() []*logspb.ResourceLogs {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*logspb.ResourceLogs(nil), c.resourceLogs...)
+}
+
+// LogRecords returns the log records of the accepted requests
+func (c *Collector) LogRecords() []*logspb.LogRecord {
+	var records []*logspb.LogRecord
+	for _, rl := range c.ResourceLogs() {
+		for _, sl := range rl.GetScopeLogs() {
+			records = append(records, sl.GetLogRecords()...)
+		}
+	}
+	return records
+}
+
+// Attributes converts OTLP attributes to a map, e.g. Attributes(span.GetAttributes())["http.route"]
+func Attributes(kvs []*commonpb.KeyValue) map[string]any {
+	attrs := make(map[string]any, len(kvs))
+	for _, kv := range kvs {
+		attrs[kv.GetKey()] = anyValue(kv.GetValue())
+	}
+	return attrs
+}
+
+// anyValue converts an OTLP value to its Go value
+func anyValue(v *commonpb.AnyValue) any {
+	switch value := v.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return value.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return value.BoolValue
+	case *commonpb.AnyValue_IntValue:
+		return value.IntValue
+	case *commonpb.AnyValue_DoubleValue:
+		return value.DoubleValue
+	case *commonpb.AnyValue_BytesValue:
+		return value.BytesValue
+	case *commonpb.AnyValue_ArrayValue:
+		values := make([]any, len(value.ArrayValue.GetValues()))
+		for i, item := range value.ArrayValue.GetValues() {
+			values[i] = anyValue(item)
+		}
+		return values
+	case *commonpb.AnyValue_KvlistValue:
+		return Attributes(value.KvlistValue.GetValues())
+	default:
+		return nil
+	}
+}
+
+// nextResponse returns the scripted response to req
+func (c *Collector) nextResponse(req Request) Response {
+	c.mu.Lock()
+	if len(c.queue) > 0 {
+		response := c.queue[0]
+		c.queue = c.queue[1:]
+		c.mu.Unlock()
+		return response
+	}
+	respond := c.respond
+	c.mu.Unlock()
+
+	if respond != nil {
+		return respond(req)
+	}
+	return Response{}
+}
+
+// record stores a request and, when it was accepted, its payload
+func (c *Collector) record(req Request, store func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = append(c.requests, req)
+	if store != nil {
+		store()
+	}
+}
@@ -0,0 +1,344 @@
+package collector_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	tel "github.com/Doraverse-Workspace/open-observe/otel"
+	"github.com/Doraverse-Workspace/open-observe/oteltest/collector"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// traceExportPath is the full method of the OTLP/gRPC trace service
+const traceExportPath = "/opentelemetry.proto.collector.trace.v1.TraceService/Export"
+
+// fastRetry retries quickly so the scripted failures do not slow the tests down
+var fastRetry = struct {
+	http otlptracehttp.RetryConfig
+	grpc otlptracegrpc.RetryConfig
+}{
+	http: otlptracehttp.RetryConfig{Enabled: true, InitialInterval: 10 * time.Millisecond, MaxInterval: 50 * time.Millisecond, MaxElapsedTime: 5 * time.Second},
+	grpc: otlptracegrpc.RetryConfig{Enabled: true, InitialInterval: 10 * time.Millisecond, MaxInterval: 50 * time.Millisecond, MaxElapsedTime: 5 * time.Second},
+}
+
+// exporters returns an OTLP/HTTP and an OTLP/gRPC span exporter of c by protocol
+func exporters(t *testing.T, c *collector.Collector, timeout time.Duration, retry bool) map[string]sdktrace.SpanExporter {
+	t.Helper()
+
+	httpRetry, grpcRetry := fastRetry.http, fastRetry.grpc
+	httpRetry.Enabled, grpcRetry.Enabled = retry, retry
+	httpExporter, err := otlptracehttp.New(context.Background(),
+		otlptracehttp.WithEndpoint(c.HTTPEndpoint()),
+		otlptracehttp.WithURLPath("/api/default/v1/traces"),
+		otlptracehttp.WithInsecure(),
+		otlptracehttp.WithTimeout(timeout),
+		otlptracehttp.WithRetry(httpRetry),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcExporter, err := otlptracegrpc.New(context.Background(),
+		otlptracegrpc.WithEndpoint(c.GRPCEndpoint()),
+		otlptracegrpc.WithInsecure(),
+		otlptracegrpc.WithTimeout(timeout),
+		otlptracegrpc.WithRetry(grpcRetry),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = httpExporter.Shutdown(context.Background())
+		_ = grpcExporter.Shutdown(context.Background())
+	})
+
+	return map[string]sdktrace.SpanExporter{"http": httpExporter, "grpc": grpcExporter}
+}
+
+// spans returns a single span named name
+func spans(name string) []sdktrace.ReadOnlySpan {
+	return tracetest.SpanStubs{{Name: name, Attributes: []attribute.KeyValue{attribute.String("order.id", "o-1")}}}.Snapshots()
+}
+
+// statuses returns the status of each request
+func statuses(requests []collector.Request) []int {
+	var statuses []int
+	for _, req := range requests {
+		statuses = append(statuses, req.Status)
+	}
+	return statuses
+}
+
+func TestInitTracerHTTPRoundTrip(t *testing.T) {
+	c := collector.New(t)
+	tp := tel.InitTracerHTTP(tel.Config{
+		ServiceName: "orders",
+		Endpoint:    c.HTTPEndpoint(),
+		BasicAuth:   "dXNlcjpwYXNz",
+		StreamName:  "orders",
+	})
+	t.Cleanup(func() {
+		_ = tp.Shutdown(context.Background())
+	})
+
+	_, s := tp.Tracer("test").Start(context.Background(), "checkout")
+	s.SetAttributes(attribute.String("order.id", "o-1"))
+	s.End()
+	if err := tp.ForceFlush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := c.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("%d requests, want 1", len(requests))
+	}
+	req := requests[0]
+	if req.Protocol != "http" || req.Signal != collector.SignalTraces || req.Path != "/api/default/v1/traces" {
+		t.Errorf("request = %s %s %s, want http traces /api/default/v1/traces", req.Protocol, req.Signal, req.Path)
+	}
+	if req.ContentType != "application/x-protobuf" || req.Status != http.StatusOK || req.Items != 1 {
+		t.Errorf("request = %s, status %d, %d items, want application/x-protobuf, 200 and 1 item", req.ContentType, req.Status, req.Items)
+	}
+	if got := req.Header.Get("stream-name"); got != "orders" {
+		t.Errorf("stream-name = %q, want orders", got)
+	}
+	if got := req.Header.Get("Authorization"); got != "Basic dXNlcjpwYXNz" {
+		t.Errorf("Authorization = %q, want Basic dXNlcjpwYXNz", got)
+	}
+
+	checkout := c.Span("checkout")
+	if checkout == nil {
+		t.Fatalf("spans = %v, want checkout", c.SpanNames())
+	}
+	if got := collector.Attributes(checkout.GetAttributes())["order.id"]; got != "o-1" {
+		t.Errorf("order.id = %v, want o-1", got)
+	}
+	resource := collector.Attributes(c.ResourceSpans()[0].GetResource().GetAttributes())
+	if resource["service.name"] != "orders" {
+		t.Errorf("service.name = %v, want orders", resource["service.name"])
+	}
+}
+
+func TestInitTracerGRPCRoundTrip(t *testing.T) {
+	c := collector.New(t)
+	tp := tel.InitTracerGRPC(tel.Config{
+		ServiceName:  "orders",
+		Endpoint:     c.GRPCEndpoint(),
+		BasicAuth:    "dXNlcjpwYXNz",
+		Organization: "acme",
+		StreamName:   "orders",
+	})
+	t.Cleanup(func() {
+		_ = tp.Shutdown(context.Background())
+	})
+
+	_, s := tp.Tracer("test").Start(context.Background(), "checkout")
+	s.End()
+	if err := tp.ForceFlush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := c.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("%d requests, want 1", len(requests))
+	}
+	req := requests[0]
+	if req.Protocol != "grpc" || req.Signal != collector.SignalTraces || req.Path != traceExportPath {
+		t.Errorf("request = %s %s %s, want grpc traces %s", req.Protocol, req.Signal, req.Path, traceExportPath)
+	}
+	if req.ContentType != "application/grpc" || req.Status != http.StatusOK || req.Items != 1 {
+		t.Errorf("request = %s, status %d, %d items, want application/grpc, 200 and 1 item", req.ContentType, req.Status, req.Items)
+	}
+	if got := req.Header.Get("organization"); got != "acme" {
+		t.Errorf("organization = %q, want acme", got)
+	}
+	if got := req.Header.Get("stream-name"); got != "orders" {
+		t.Errorf("stream-name = %q, want orders", got)
+	}
+	if names := c.SpanNames(); len(names) != 1 || names[0] != "checkout" {
+		t.Errorf("spans = %v, want [checkout]", names)
+	}
+}
+
+func TestMetricsAndLogsRoundTrip(t *testing.T) {
+	c := collector.New(t)
+	config := tel.Config{ServiceName: "orders", Endpoint: c.HTTPEndpoint(), StreamName: "orders"}
+
+	mp := tel.InitMeterHTTP(config)
+	counter, err := mp.Meter("test").Int64Counter("orders.created")
+	if err != nil {
+		t.Fatal(err)
+	}
+	counter.Add(context.Background(), 3)
+	if err := mp.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	lp := tel.InitLoggerHTTP(config)
+	logger := slog.New(tel.NewSlogHandler(config, tel.SlogHandlerOptions{LoggerProvider: lp}))
+	logger.Info("order created", slog.String("order.id", "o-1"))
+	if err := lp.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	metric := c.Metric("orders.created")
+	if metric == nil {
+		t.Fatal("metric orders.created was not received")
+	}
+	if got := metric.GetSum().GetDataPoints()[0].GetAsInt(); got != 3 {
+		t.Errorf("orders.created = %d, want 3", got)
+	}
+	records := c.LogRecords()
+	if len(records) != 1 || records[0].GetBody().GetStringValue() != "order created" {
+		t.Fatalf("log records = %v, want order created", records)
+	}
+	if got := collector.Attributes(records[0].GetAttributes())["order.id"]; got != "o-1" {
+		t.Errorf("order.id = %v, want o-1", got)
+	}
+
+	paths := map[string]bool{}
+	for _, req := range c.Requests() {
+		paths[req.Path] = true
+	}
+	if !paths["/api/default/v1/metrics"] || !paths["/api/default/v1/logs"] {
+		t.Errorf("paths = %v, want /api/default/v1/metrics and /api/default/v1/logs", paths)
+	}
+}
+
+func TestScriptedFailuresAreRetried(t *testing.T) {
+	c := collector.New(t)
+	for protocol, exporter := range exporters(t, c, 5*time.Second, true) {
+		t.Run(protocol, func(t *testing.T) {
+			c.Reset()
+			throttled := collector.Response{Status: http.StatusTooManyRequests}
+			if protocol == "grpc" {
+				// The gRPC exporter only retries ResourceExhausted with RetryInfo,
+				// over HTTP Retry-After is rounded up to whole seconds
+				throttled.RetryAfter = 10 * time.Millisecond
+			}
+			c.Enqueue(collector.Response{Status: http.StatusServiceUnavailable}, throttled)
+
+			if err := exporter.ExportSpans(context.Background(), spans("checkout")); err != nil {
+				t.Fatalf("ExportSpans() error = %v, want the third attempt accepted", err)
+			}
+
+			requests := c.Requests()
+			if got := statuses(requests); len(got) != 3 || got[0] != 503 || got[1] != 429 || got[2] != 200 {
+				t.Errorf("statuses = %v, want [503 429 200]", got)
+			}
+			if requests[0].Items != 0 || requests[2].Items != 1 {
+				t.Errorf("items = %d and %d, want 0 for the failed request and 1 for the accepted one", requests[0].Items, requests[2].Items)
+			}
+			if names := c.SpanNames(); len(names) != 1 || names[0] != "checkout" {
+				t.Errorf("spans = %v, want the accepted checkout only", names)
+			}
+		})
+	}
+}
+
+func TestPermanentFailureIsNotRetried(t *testing.T) {
+	c := collector.New(t)
+	for protocol, exporter := range exporters(t, c, 5*time.Second, true) {
+		t.Run(protocol, func(t *testing.T) {
+			c.Reset()
+			c.RespondWith(func(collector.Request) collector.Response {
+				return collector.Response{Status: http.StatusBadRequest}
+			})
+
+			if err := exporter.ExportSpans(context.Background(), spans("checkout")); err == nil {
+				t.Fatal("ExportSpans() succeeded, want the 400 error")
+			}
+			if got := statuses(c.Requests()); len(got) != 1 || got[0] != 400 {
+				t.Errorf("statuses = %v, want [400]", got)
+			}
+			if n := len(c.Spans()); n != 0 {
+				t.Errorf("%d spans kept, want 0", n)
+			}
+		})
+	}
+}
+
+func TestDelayedResponseTimesOut(t *testing.T) {
+	c := collector.New(t)
+	for protocol, exporter := range exporters(t, c, 50*time.Millisecond, false) {
+		t.Run(protocol, func(t *testing.T) {
+			c.Reset()
+			c.Enqueue(collector.Response{Delay: 2 * time.Second})
+
+			if err := exporter.ExportSpans(context.Background(), spans("checkout")); err == nil {
+				t.Fatal("ExportSpans() succeeded, want a timeout")
+			}
+			if got := statuses(c.WaitForRequests(1, time.Second)); got[0] != 499 {
+				t.Errorf("statuses = %v, want [499]", got)
+			}
+		})
+	}
+}
+
+func TestHTTPJSONAndGzip(t *testing.T) {
+	c := collector.New(t)
+	url := "http://" + c.HTTPEndpoint() + "/api/default/v1/traces"
+
+	payload, err := protojson.Marshal(&coltracepb.ExportTraceServiceRequest{
+		ResourceSpans: []*tracepb.ResourceSpans{{
+			ScopeSpans: []*tracepb.ScopeSpans{{
+				Spans: []*tracepb.Span{{Name: "json", TraceId: bytes.Repeat([]byte{1}, 16), SpanId: bytes.Repeat([]byte{2}, 8)}},
+			}},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body bytes.Buffer
+	gz := gzip.NewWriter(&body)
+	_, _ = gz.Write(payload)
+	_ = gz.Close()
+
+	post := func() *http.Response {
+		req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", "gzip")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			_ = resp.Body.Close()
+		})
+		return resp
+	}
+
+	c.Enqueue(collector.Response{Status: http.StatusTooManyRequests, RetryAfter: 1500 * time.Millisecond})
+	throttled := post()
+	if throttled.StatusCode != http.StatusTooManyRequests || throttled.Header.Get("Retry-After") != "2" {
+		t.Errorf("response = %d with Retry-After %q, want 429 with Retry-After 2", throttled.StatusCode, throttled.Header.Get("Retry-After"))
+	}
+
+	accepted := post()
+	data, _ := io.ReadAll(accepted.Body)
+	if accepted.StatusCode != http.StatusOK || accepted.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("response = %d %s %s, want 200 application/json", accepted.StatusCode, accepted.Header.Get("Content-Type"), data)
+	}
+	requests := c.Requests()
+	if len(requests) != 2 || requests[1].Compression != "gzip" || requests[1].ContentType != "application/json" || requests[1].Items != 1 {
+		t.Errorf("requests = %+v, want the second one gzipped JSON with 1 item", requests)
+	}
+	if names := c.SpanNames(); len(names) != 1 || names[0] != "json" {
+		t.Errorf("spans = %v, want [json]", names)
+	}
+}
@@ -0,0 +1,14 @@
+// Package collector is a fake OpenObserve/OTLP collector for integration tests.
+// New starts OTLP/HTTP and OTLP/gRPC receivers on local ports. They decode the
+// protobuf or JSON payloads, gzipped or not, into spans, metrics and log
+// records, and record the headers of every request, so the exporter set up by
+// InitTracerHTTP or InitTracerGRPC can be tested offline:
+//
+//	c := collector.New(t)
+//	tp := tel.InitTracerHTTP(tel.Config{Endpoint: c.HTTPEndpoint(), StreamName: "orders", BasicAuth: "dXNlcjpwYXNz"})
+//	... create spans ...
+//	_ = tp.ForceFlush(ctx)
+//	req := c.Requests()[0] // req.Path == "/api/default/v1/traces", req.Header.Get("stream-name") == "orders"
+//
+// Enqueue and RespondWith script errors, delays and 429s to test retries.
+package collector
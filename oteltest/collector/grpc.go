@@ -0,0 +1,141 @@
+package collector
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	_ "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// newGRPCServer returns a gRPC server with the OTLP trace, metrics and logs services
+func newGRPCServer(c *Collector) *grpc.Server {
+	server := grpc.NewServer()
+	coltracepb.RegisterTraceServiceServer(server, &traceService{c: c})
+	colmetricspb.RegisterMetricsServiceServer(server, &metricsService{c: c})
+	collogspb.RegisterLogsServiceServer(server, &logsService{c: c})
+	return server
+}
+
+type traceService struct {
+	coltracepb.UnimplementedTraceServiceServer
+	c *Collector
+}
+
+func (s *traceService) Export(ctx context.Context, req *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	if err := s.c.receiveGRPC(ctx, SignalTraces, req); err != nil {
+		return nil, err
+	}
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
+
+type metricsService struct {
+	colmetricspb.UnimplementedMetricsServiceServer
+	c *Collector
+}
+
+func (s *metricsService) Export(ctx context.Context, req *colmetricspb.ExportMetricsServiceRequest) (*colmetricspb.ExportMetricsServiceResponse, error) {
+	if err := s.c.receiveGRPC(ctx, SignalMetrics, req); err != nil {
+		return nil, err
+	}
+	return &colmetricspb.ExportMetricsServiceResponse{}, nil
+}
+
+type logsService struct {
+	collogspb.UnimplementedLogsServiceServer
+	c *Collector
+}
+
+func (s *logsService) Export(ctx context.Context, req *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	if err := s.c.receiveGRPC(ctx, SignalLogs, req); err != nil {
+		return nil, err
+	}
+	return &collogspb.ExportLogsServiceResponse{}, nil
+}
+
+// receiveGRPC records an OTLP/gRPC export request and returns the scripted error
+func (c *Collector) receiveGRPC(ctx context.Context, signal Signal, payload proto.Message) error {
+	method, _ := grpc.Method(ctx)
+	header := http.Header{}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for key, values := range md {
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+
+	req := Request{
+		Protocol:    "grpc",
+		Signal:      signal,
+		Path:        method,
+		Header:      header,
+		ContentType: header.Get("Content-Type"),
+		Compression: header.Get("Grpc-Encoding"),
+		Received:    time.Now(),
+	}
+
+	response := c.nextResponse(req)
+	if response.Delay > 0 {
+		select {
+		case <-time.After(response.Delay):
+		case <-ctx.Done():
+			req.Status = 499
+			c.record(req, nil)
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+	if !response.ok() {
+		req.Status = response.Status
+		c.record(req, nil)
+
+		st := status.New(grpcCode(response.Status), http.StatusText(response.Status))
+		if response.RetryAfter > 0 {
+			if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(response.RetryAfter)}); err == nil {
+				st = detailed
+			}
+		}
+		return st.Err()
+	}
+
+	req.Status = http.StatusOK
+	c.accept(req, payload)
+	return nil
+}
+
+// grpcCode maps an HTTP status to the gRPC code an OTLP receiver would return
+func grpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusOK:
+		return codes.OK
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusRequestEntityTooLarge:
+		return codes.ResourceExhausted
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	default:
+		return codes.Internal
+	}
+}
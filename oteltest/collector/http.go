@@ -0,0 +1,137 @@
+package collector
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	contentTypeProtobuf = "application/x-protobuf"
+	contentTypeJSON     = "application/json"
+)
+
+// serveHTTP receives OTLP/HTTP export requests on any path ending with
+// /v1/traces, /v1/metrics or /v1/logs, such as the OpenObserve
+// /api/<organization>/v1/traces
+func (c *Collector) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	var signal Signal
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/v1/traces"):
+		signal = SignalTraces
+	case strings.HasSuffix(r.URL.Path, "/v1/metrics"):
+		signal = SignalMetrics
+	case strings.HasSuffix(r.URL.Path, "/v1/logs"):
+		signal = SignalLogs
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	req := Request{
+		Protocol:    "http",
+		Signal:      signal,
+		Path:        r.URL.Path,
+		Header:      r.Header.Clone(),
+		ContentType: r.Header.Get("Content-Type"),
+		Compression: r.Header.Get("Content-Encoding"),
+		Received:    time.Now(),
+	}
+	jsonEncoded := strings.HasPrefix(req.ContentType, contentTypeJSON)
+
+	// The server only notices a client that gave up once the body is read
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		req.Status = http.StatusBadRequest
+		c.record(req, nil)
+		writeHTTPError(w, jsonEncoded, http.StatusBadRequest, err.Error())
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	response := c.nextResponse(req)
+	if response.Delay > 0 {
+		select {
+		case <-time.After(response.Delay):
+		case <-r.Context().Done():
+			req.Status = 499
+			c.record(req, nil)
+			return
+		}
+	}
+	if !response.ok() {
+		req.Status = response.Status
+		c.record(req, nil)
+		if response.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(response.RetryAfter.Seconds()))))
+		}
+		writeHTTPError(w, jsonEncoded, response.Status, http.StatusText(response.Status))
+		return
+	}
+
+	payload := newExportRequest(signal)
+	if err := decodeHTTPBody(r, payload, jsonEncoded); err != nil {
+		req.Status = http.StatusBadRequest
+		c.record(req, nil)
+		writeHTTPError(w, jsonEncoded, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req.Status = http.StatusOK
+	c.accept(req, payload)
+	writeHTTPMessage(w, jsonEncoded, http.StatusOK, newExportResponse(signal))
+}
+
+// decodeHTTPBody decodes the possibly gzipped protobuf or JSON body of r into payload
+func decodeHTTPBody(r *http.Request, payload proto.Message, jsonEncoded bool) error {
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if jsonEncoded {
+		return protojson.Unmarshal(data, payload)
+	}
+	return proto.Unmarshal(data, payload)
+}
+
+// writeHTTPError writes a google.rpc.Status body, as OTLP/HTTP receivers do
+func writeHTTPError(w http.ResponseWriter, jsonEncoded bool, code int, message string) {
+	writeHTTPMessage(w, jsonEncoded, code, &status.Status{Code: int32(grpcCode(code)), Message: message})
+}
+
+// writeHTTPMessage writes message in the encoding of the request
+func writeHTTPMessage(w http.ResponseWriter, jsonEncoded bool, code int, message proto.Message) {
+	var data []byte
+	if jsonEncoded {
+		data, _ = protojson.Marshal(message)
+		w.Header().Set("Content-Type", contentTypeJSON)
+	} else {
+		data, _ = proto.Marshal(message)
+		w.Header().Set("Content-Type", contentTypeProtobuf)
+	}
+	w.WriteHeader(code)
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,65 @@
+package collector
+
+import (
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// newExportRequest returns an empty export request of signal
+func newExportRequest(signal Signal) proto.Message {
+	switch signal {
+	case SignalMetrics:
+		return &colmetricspb.ExportMetricsServiceRequest{}
+	case SignalLogs:
+		return &collogspb.ExportLogsServiceRequest{}
+	default:
+		return &coltracepb.ExportTraceServiceRequest{}
+	}
+}
+
+// newExportResponse returns the successful response to an export request of signal
+func newExportResponse(signal Signal) proto.Message {
+	switch signal {
+	case SignalMetrics:
+		return &colmetricspb.ExportMetricsServiceResponse{}
+	case SignalLogs:
+		return &collogspb.ExportLogsServiceResponse{}
+	default:
+		return &coltracepb.ExportTraceServiceResponse{}
+	}
+}
+
+// accept records an accepted request with its decoded payload
+func (c *Collector) accept(req Request, payload proto.Message) {
+	switch payload := payload.(type) {
+	case *coltracepb.ExportTraceServiceRequest:
+		for _, rs := range payload.GetResourceSpans() {
+			for _, ss := range rs.GetScopeSpans() {
+				req.Items += len(ss.GetSpans())
+			}
+		}
+		c.record(req, func() {
+			c.resourceSpans = append(c.resourceSpans, payload.GetResourceSpans()...)
+		})
+	case *colmetricspb.ExportMetricsServiceRequest:
+		for _, rm := range payload.GetResourceMetrics() {
+			for _, sm := range rm.GetScopeMetrics() {
+				req.Items += len(sm.GetMetrics())
+			}
+		}
+		c.record(req, func() {
+			c.resourceMetrics = append(c.resourceMetrics, payload.GetResourceMetrics()...)
+		})
+	case *collogspb.ExportLogsServiceRequest:
+		for _, rl := range payload.GetResourceLogs() {
+			for _, sl := range rl.GetScopeLogs() {
+				req.Items += len(sl.GetLogRecords())
+			}
+		}
+		c.record(req, func() {
+			c.resourceLogs = append(c.resourceLogs, payload.GetResourceLogs()...)
+		})
+	}
+}
@@ -0,0 +1,17 @@
+// Package oteltest records spans in memory for unit tests and asserts on them.
+// New installs an in-memory TracerProvider as the global provider for the
+// duration of a test, Find and Child look spans up by name, and SpanAssert
+// checks their attributes, status, kind, events and parent/child relationships:
+//
+//	r := oteltest.New(t)
+//	_, spans := r.ServeEcho(tel.Config{ServiceName: "users"}, "/users/:id", handler.GetUser, req)
+//	spans.Find("/users/:id").
+//		HasKind(trace.SpanKindServer).
+//		HasAttribute("status.code", 200).
+//		HasChild("MongoDB.FindOne")
+//
+// MatchSnapshot compares the normalized span tree with a golden file under
+// testdata. oteltest registers no flag: pass WithUpdate(*update) with the
+// -update flag of the test package, or set OTELTEST_UPDATE=1, to rewrite the
+// golden files.
+package oteltest
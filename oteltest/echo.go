@@ -0,0 +1,26 @@
+package oteltest
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	tel "github.com/Doraverse-Workspace/open-observe/otel"
+	"github.com/labstack/echo/v4"
+)
+
+// ServeEcho serves req with handler registered on route behind OtelMiddleware,
+// e.g. ServeEcho(config, "/users/:id", handler, httptest.NewRequest("GET", "/users/42", nil)),
+// and returns the response and the spans recorded since the last Reset. The
+// recorder must be created with New since the middleware uses the global
+// provider.
+func (r *Recorder) ServeEcho(config tel.Config, route string, handler echo.HandlerFunc, req *http.Request, opts ...tel.MiddlewareOption) (*httptest.ResponseRecorder, Spans) {
+	r.tb.Helper()
+
+	e := echo.New()
+	e.Use(tel.OtelMiddleware(config, opts...))
+	e.Add(req.Method, route, handler)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec, r.Spans()
+}
@@ -0,0 +1,162 @@
+package oteltest
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// updateEnv rewrites the golden files when set to a true value, e.g.
+// OTELTEST_UPDATE=1 go test ./..., for packages without an -update flag
+const updateEnv = "OTELTEST_UPDATE"
+
+// WithUpdate rewrites the golden file instead of comparing with it when update
+// is true, e.g. WithUpdate(*update) with the -update flag of the test package
+func WithUpdate(update bool) SnapshotOption {
+	return func(c *snapshotConfig) {
+		c.update = &update
+	}
+}
+
+// shouldUpdate reports whether MatchSnapshot rewrites the golden file: the value
+// of WithUpdate, else the -update flag when the test binary defines one, else
+// OTELTEST_UPDATE. The flag is looked up when the snapshot is matched, oteltest
+// does not register it.
+func shouldUpdate(cfg snapshotConfig) bool {
+	if cfg.update != nil {
+		return *cfg.update
+	}
+	if f := flag.Lookup("update"); f != nil {
+		if update, err := strconv.ParseBool(f.Value.String()); err == nil {
+			return update
+		}
+	}
+	update, _ := strconv.ParseBool(os.Getenv(updateEnv))
+	return update
+}
+
+// MatchSnapshot compares the Snapshot of the spans with the golden file
+// testdata/<name>.golden, reporting a line diff when they differ. Run the tests
+// with -update (see WithUpdate) or OTELTEST_UPDATE=1 to write the golden files,
+// then review them like code.
+func (s Spans) MatchSnapshot(name string, opts ...SnapshotOption) {
+	s.tb.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	got := s.Snapshot(opts...)
+
+	if shouldUpdate(newSnapshotConfig(opts)) {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			s.tb.Fatalf("oteltest: failed to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			s.tb.Fatalf("oteltest: failed to write %s: %v", path, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		s.tb.Fatalf("oteltest: golden file %s does not exist, run the test with -update or %s=1 to create it", path, updateEnv)
+	}
+	if err != nil {
+		s.tb.Fatalf("oteltest: failed to read %s: %v", path, err)
+	}
+	if string(want) != got {
+		s.tb.Errorf("oteltest: spans differ from %s (-want +got), run the test with -update to accept them:\n%s", path, lineDiff(string(want), got))
+	}
+}
+
+// MatchSnapshot compares the ended spans with the golden file testdata/<name>.golden
+func (r *Recorder) MatchSnapshot(name string, opts ...SnapshotOption) {
+	r.tb.Helper()
+	r.Spans().MatchSnapshot(name, opts...)
+}
+
+// diffContext is the number of unchanged lines shown around changes
+const diffContext = 3
+
+// lineDiff returns a unified-style diff of want and got: removed lines start with
+// "-", added ones with "+" and unchanged ones with " ", with the span headers
+// kept so the changed span can be told apart
+func lineDiff(want, got string) string {
+	a := strings.Split(strings.TrimSuffix(want, "\n"), "\n")
+	b := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type line struct {
+		op   byte
+		text string
+	}
+	var lines []line
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, line{' ', a[i]})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, line{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, line{'+', b[j]})
+			j++
+		}
+	}
+
+	// Keep the changed lines, diffContext lines around them and the header of
+	// the span they belong to
+	keep := make([]bool, len(lines))
+	for k, l := range lines {
+		if l.op == ' ' {
+			continue
+		}
+		for c := max(0, k-diffContext); c <= min(len(lines)-1, k+diffContext); c++ {
+			keep[c] = true
+		}
+		for c := k; c >= 0; c-- {
+			if strings.HasPrefix(strings.TrimSpace(lines[c].text), "span ") {
+				keep[c] = true
+				break
+			}
+		}
+	}
+
+	var sb strings.Builder
+	skipped := false
+	for k, l := range lines {
+		if !keep[k] {
+			skipped = true
+			continue
+		}
+		if skipped {
+			sb.WriteString("  ...\n")
+			skipped = false
+		}
+		fmt.Fprintf(&sb, "%c %s\n", l.op, l.text)
+	}
+	if skipped {
+		sb.WriteString("  ...\n")
+	}
+	return sb.String()
+}
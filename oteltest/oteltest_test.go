@@ -0,0 +1,272 @@
+package oteltest_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	tel "github.com/Doraverse-Workspace/open-observe/otel"
+	"github.com/Doraverse-Workspace/open-observe/oteltest"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// fakeTB records the failures of assertions instead of failing the test. Fatalf
+// stops the goroutine like testing.T does, so fatal assertions run with run.
+type fakeTB struct {
+	testing.TB
+	errors []string
+	fatal  string
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeTB) Fatalf(format string, args ...any) {
+	f.fatal = fmt.Sprintf(format, args...)
+	runtime.Goexit()
+}
+
+// run calls fn in its own goroutine and reports whether it returned, false when
+// a Fatalf stopped it
+func (f *fakeTB) run(fn func()) bool {
+	returned := false
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+		returned = true
+	}()
+	<-done
+	return returned
+}
+
+// recordTree records the spans root <- child <- grandchild and a second root
+func recordTree(r *oteltest.Recorder) {
+	tracer := r.Tracer("test")
+	ctx, root := tracer.Start(context.Background(), "root", trace.WithSpanKind(trace.SpanKindServer))
+	childCtx, child := tracer.Start(ctx, "child", trace.WithAttributes(
+		attribute.Int("int", 200),
+		attribute.Int64("int64", 5),
+		attribute.Float64("float", 1.5),
+		attribute.String("string", "value"),
+		attribute.StringSlice("strings", []string{"a", "b"}),
+	))
+	_, grandchild := tracer.Start(childCtx, "grandchild")
+	child.AddEvent("retry", trace.WithAttributes(attribute.Int("attempt", 2)))
+	child.SetStatus(codes.Error, "failed")
+	grandchild.End()
+	child.End()
+	root.End()
+
+	_, other := tracer.Start(context.Background(), "other")
+	other.End()
+}
+
+func TestHasAttributeNumbers(t *testing.T) {
+	r := oteltest.NewLocal(t)
+	recordTree(r)
+
+	r.Find("child").
+		HasAttribute("int", 200).
+		HasAttribute("int", int64(200)).
+		HasAttribute("int", int32(200)).
+		HasAttribute("int64", 5).
+		HasAttribute("int64", int64(5)).
+		HasAttribute("float", 1.5).
+		HasAttribute("string", "value").
+		HasAttribute("strings", []string{"a", "b"}).
+		HasAttributeKey("int64").
+		LacksAttribute("missing")
+}
+
+func TestFailedAssertionsAreReported(t *testing.T) {
+	fake := &fakeTB{TB: t}
+	r := oteltest.NewLocal(fake)
+	recordTree(r)
+
+	r.Find("child").
+		HasAttribute("int", "200").
+		HasAttribute("int", 201).
+		HasAttribute("float", 1).
+		HasAttribute("missing", 1).
+		HasAttributeKey("missing").
+		LacksAttribute("string").
+		HasStatus(codes.Ok).
+		HasStatus(codes.Error, "other").
+		HasKind(trace.SpanKindClient).
+		HasEvent("retry", attribute.Int("attempt", 3)).
+		HasEvent("missing").
+		IsRoot().
+		HasParent("other").
+		HasChild("missing")
+
+	if len(fake.errors) != 14 {
+		t.Errorf("%d failures reported, want 14:\n%s", len(fake.errors), strings.Join(fake.errors, "\n"))
+	}
+	if fake.fatal != "" {
+		t.Errorf("Fatalf(%q) called, want Errorf only", fake.fatal)
+	}
+}
+
+func TestChildAndParent(t *testing.T) {
+	r := oteltest.NewLocal(t)
+	recordTree(r)
+
+	spans := r.Spans()
+	spans.Find("root").IsRoot().HasKind(trace.SpanKindServer).HasChild("child")
+	spans.Find("root").
+		Child("child").
+		HasParent("root").
+		HasStatus(codes.Error, "failed").
+		HasEvent("retry", attribute.Int("attempt", 2)).
+		Child("grandchild").
+		Parent().
+		Parent().
+		IsRoot()
+
+	if got := spans.Roots().Names(); len(got) != 2 || got[0] != "root" || got[1] != "other" {
+		t.Errorf("Roots() = %v, want [root other]", got)
+	}
+	if got := spans.ChildrenOf(spans.Find("root").Span()).Names(); len(got) != 1 || got[0] != "child" {
+		t.Errorf("ChildrenOf(root) = %v, want [child]", got)
+	}
+}
+
+func TestFatalAssertions(t *testing.T) {
+	tests := []struct {
+		name   string
+		assert func(r *oteltest.Recorder)
+		want   string
+	}{
+		{
+			name:   "Child",
+			assert: func(r *oteltest.Recorder) { r.Find("root").Child("grandchild") },
+			want:   `span "root" has no child "grandchild", children: [child]`,
+		},
+		{
+			name:   "Parent",
+			assert: func(r *oteltest.Recorder) { r.Find("other").Parent() },
+			want:   `span "other" has no recorded parent`,
+		},
+		{
+			name:   "Find",
+			assert: func(r *oteltest.Recorder) { r.Find("missing") },
+			want:   `no span named "missing", recorded spans: [grandchild, child, root, other]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeTB{TB: t}
+			r := oteltest.NewLocal(fake)
+			recordTree(r)
+
+			if fake.run(func() { tt.assert(r) }) {
+				t.Fatal("the assertion returned, want it to stop the test")
+			}
+			if !strings.Contains(fake.fatal, tt.want) {
+				t.Errorf("Fatalf(%q), want %q", fake.fatal, tt.want)
+			}
+		})
+	}
+}
+
+func TestReset(t *testing.T) {
+	r := oteltest.NewLocal(t)
+	recordTree(r)
+	r.Reset()
+
+	if n := r.Spans().Len(); n != 0 {
+		t.Errorf("%d spans after Reset, want 0", n)
+	}
+}
+
+func TestServeEcho(t *testing.T) {
+	r := oteltest.New(t)
+
+	rec, spans := r.ServeEcho(tel.Config{ServiceName: "users"}, "/users/:id", func(c echo.Context) error {
+		_, span := r.Tracer("handler").Start(c.Request().Context(), "load user")
+		span.End()
+		return c.String(http.StatusOK, c.Param("id"))
+	}, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "42" {
+		t.Errorf("response = %d %q, want 200 \"42\"", rec.Code, rec.Body.String())
+	}
+	spans.Find("/users/:id").
+		IsRoot().
+		HasKind(trace.SpanKindServer).
+		HasAttribute("status.code", 200).
+		HasChild("load user")
+}
+
+func TestSnapshotMasksPerRunIDs(t *testing.T) {
+	r := oteltest.NewLocal(t)
+	_, span := r.Tracer("test").Start(context.Background(), "MongoDB.find", trace.WithAttributes(
+		attribute.String("db.connection_id", "localhost:27017[-7]"),
+		attribute.Int64("db.mongodb.request_id", 1234),
+		attribute.Int64("db.mongodb.cursor_id", 987654321),
+		attribute.Float64("duration.ms", 1.25),
+		attribute.String("db.collection", "users"),
+	))
+	span.End()
+
+	want := `span MongoDB.find
+  kind: internal
+  status: Unset
+  attributes:
+    db.collection: "users"
+    db.connection_id: <masked>
+    db.mongodb.cursor_id: <masked>
+    db.mongodb.request_id: <masked>
+    duration.ms: <duration>
+`
+	if got := r.Spans().Snapshot(); got != want {
+		t.Errorf("Snapshot() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMatchSnapshotUpdate(t *testing.T) {
+	t.Chdir(t.TempDir())
+	r := oteltest.NewLocal(t)
+	recordTree(r)
+
+	r.MatchSnapshot("tree", oteltest.WithUpdate(true))
+	golden, err := os.ReadFile(filepath.Join("testdata", "tree.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(golden) != r.Spans().Snapshot() {
+		t.Errorf("golden file =\n%s\nwant the snapshot", golden)
+	}
+	r.MatchSnapshot("tree", oteltest.WithUpdate(false))
+
+	fake := &fakeTB{TB: t}
+	changed := oteltest.NewLocal(fake)
+	recordTree(changed)
+	_, extra := changed.Tracer("test").Start(context.Background(), "extra")
+	extra.End()
+	changed.MatchSnapshot("tree", oteltest.WithUpdate(false))
+	if len(fake.errors) != 1 || !strings.Contains(fake.errors[0], "+ span extra") {
+		t.Errorf("failures = %q, want a diff adding span extra", fake.errors)
+	}
+
+	if fake.run(func() { changed.MatchSnapshot("missing", oteltest.WithUpdate(false)) }) {
+		t.Fatal("MatchSnapshot returned, want it to stop the test without a golden file")
+	}
+	if !strings.Contains(fake.fatal, "testdata/missing.golden does not exist") {
+		t.Errorf("Fatalf(%q), want the missing golden file", fake.fatal)
+	}
+}
@@ -0,0 +1,91 @@
+package oteltest
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// initialTracerProvider is the global provider before any SetTracerProvider, it
+// cannot be set back
+var initialTracerProvider = otel.GetTracerProvider()
+
+// Recorder records the spans of a test in memory
+type Recorder struct {
+	tb             testing.TB
+	TracerProvider *sdktrace.TracerProvider
+	spans          *tracetest.SpanRecorder
+}
+
+// New creates a Recorder and installs its TracerProvider as the global provider,
+// with the TraceContext and Baggage propagators set by InitTracerHTTP, so code
+// using otel.Tracer, such as OtelMiddleware, records into it. The previous
+// provider is restored when the test ends. Tests using New must not run in
+// parallel because the provider is global.
+func New(tb testing.TB) *Recorder {
+	tb.Helper()
+
+	r := NewLocal(tb)
+	previous := otel.GetTracerProvider()
+	previousPropagator := otel.GetTextMapPropagator()
+	otel.SetTracerProvider(r.TracerProvider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	tb.Cleanup(func() {
+		if previous == initialTracerProvider {
+			previous = noop.NewTracerProvider()
+		}
+		otel.SetTracerProvider(previous)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return r
+}
+
+// NewLocal creates a Recorder without touching the global provider, pass
+// TracerProvider to code taking a provider, e.g. mongootel.WithTracerProvider.
+// It can be used in parallel tests.
+func NewLocal(tb testing.TB) *Recorder {
+	tb.Helper()
+
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithSpanProcessor(spans),
+	)
+	tb.Cleanup(func() {
+		_ = tp.Shutdown(context.Background())
+	})
+
+	return &Recorder{tb: tb, TracerProvider: tp, spans: spans}
+}
+
+// Tracer returns a tracer of the recorder's provider
+func (r *Recorder) Tracer(name string) trace.Tracer {
+	return r.TracerProvider.Tracer(name)
+}
+
+// Spans returns the ended spans in the order they ended
+func (r *Recorder) Spans() Spans {
+	return Spans{tb: r.tb, spans: r.spans.Ended()}
+}
+
+// Reset drops the spans recorded so far
+func (r *Recorder) Reset() {
+	r.spans.Reset()
+}
+
+// Find returns the assertions of the first ended span named name, failing the
+// test when there is none
+func (r *Recorder) Find(name string) *SpanAssert {
+	r.tb.Helper()
+	return r.Spans().Find(name)
+}
@@ -0,0 +1,190 @@
+package oteltest
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+var (
+	uuidPattern      = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hexIDPattern     = regexp.MustCompile(`^([0-9a-f]{16}|[0-9a-f]{24}|[0-9a-f]{32})$`)
+	timestampPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}`)
+)
+
+// SnapshotOption configures Snapshot
+type SnapshotOption func(*snapshotConfig)
+
+type snapshotConfig struct {
+	masked  map[string]bool
+	omitted map[string]bool
+	update  *bool
+}
+
+// perRunAttributes are masked in every snapshot, the driver numbers them per
+// connection, command or cursor so they differ between runs
+var perRunAttributes = []string{
+	"db.connection_id",
+	"db.mongodb.request_id",
+	"db.mongodb.cursor_id",
+	"db.mongodb.previous_cursor_id",
+}
+
+// newSnapshotConfig applies opts to the default configuration
+func newSnapshotConfig(opts []SnapshotOption) snapshotConfig {
+	cfg := snapshotConfig{masked: map[string]bool{}, omitted: map[string]bool{}}
+	for _, key := range perRunAttributes {
+		cfg.masked[key] = true
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// WithMaskedAttributes replaces the values of the attributes keys with
+// <masked>, for values that change between runs and are not normalized already
+func WithMaskedAttributes(keys ...string) SnapshotOption {
+	return func(c *snapshotConfig) {
+		for _, key := range keys {
+			c.masked[key] = true
+		}
+	}
+}
+
+// WithoutAttributes leaves the attributes keys out of the snapshot
+func WithoutAttributes(keys ...string) SnapshotOption {
+	return func(c *snapshotConfig) {
+		for _, key := range keys {
+			c.omitted[key] = true
+		}
+	}
+}
+
+// Snapshot renders the spans as a tree of names, kinds, statuses, attributes
+// and events, with the children of a span under it in the order they started.
+// Span IDs, timestamps and durations are left out, and attribute values that
+// change between runs are normalized: UUIDs and hex IDs become <id>, timestamps
+// <timestamp>, durations (keys named duration or ending with _ms or .ms) <duration>,
+// stack traces <stacktrace>, and the MongoDB connection, request and cursor IDs
+// <masked>.
+func (s Spans) Snapshot(opts ...SnapshotOption) string {
+	cfg := newSnapshotConfig(opts)
+
+	var sb strings.Builder
+	for _, root := range sortedByStart(s.Roots().spans) {
+		s.writeSpan(&sb, root, 0, cfg)
+	}
+	return sb.String()
+}
+
+// writeSpan writes span and its children indented by depth
+func (s Spans) writeSpan(sb *strings.Builder, span sdktrace.ReadOnlySpan, depth int, cfg snapshotConfig) {
+	indent := strings.Repeat("    ", depth)
+
+	fmt.Fprintf(sb, "%sspan %s\n", indent, span.Name())
+	fmt.Fprintf(sb, "%s  kind: %s\n", indent, span.SpanKind())
+	status := span.Status()
+	if status.Description != "" {
+		fmt.Fprintf(sb, "%s  status: %s %q\n", indent, status.Code, status.Description)
+	} else {
+		fmt.Fprintf(sb, "%s  status: %s\n", indent, status.Code)
+	}
+
+	writeAttributes(sb, indent+"  ", "attributes", span.Attributes(), cfg)
+	if events := span.Events(); len(events) > 0 {
+		fmt.Fprintf(sb, "%s  events:\n", indent)
+		for _, event := range events {
+			fmt.Fprintf(sb, "%s    - %s\n", indent, event.Name)
+			writeAttributes(sb, indent+"      ", "", event.Attributes, cfg)
+		}
+	}
+	if links := span.Links(); len(links) > 0 {
+		fmt.Fprintf(sb, "%s  links: %d\n", indent, len(links))
+	}
+
+	children := sortedByStart(s.ChildrenOf(span).spans)
+	if len(children) > 0 {
+		fmt.Fprintf(sb, "%s  children:\n", indent)
+		for _, child := range children {
+			s.writeSpan(sb, child, depth+1, cfg)
+		}
+	}
+}
+
+// writeAttributes writes the normalized attributes sorted by key under title,
+// or without a title when it is empty
+func writeAttributes(sb *strings.Builder, indent, title string, attrs []attribute.KeyValue, cfg snapshotConfig) {
+	values := map[string]string{}
+	for _, kv := range attrs {
+		key := string(kv.Key)
+		if cfg.omitted[key] {
+			continue
+		}
+		values[key] = normalizeValue(key, kv.Value, cfg)
+	}
+	if len(values) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	if title != "" {
+		fmt.Fprintf(sb, "%s%s:\n", indent, title)
+		indent += "  "
+	}
+	for _, key := range keys {
+		fmt.Fprintf(sb, "%s%s: %s\n", indent, key, values[key])
+	}
+}
+
+// normalizeValue formats an attribute value, replacing the values that change
+// between runs with placeholders
+func normalizeValue(key string, value attribute.Value, cfg snapshotConfig) string {
+	switch {
+	case cfg.masked[key]:
+		return "<masked>"
+	case key == "exception.stacktrace":
+		return "<stacktrace>"
+	case key == "duration" || strings.HasSuffix(key, ".duration") || strings.HasSuffix(key, "_ms") || strings.HasSuffix(key, ".ms"):
+		return "<duration>"
+	}
+
+	switch value.Type() {
+	case attribute.STRING:
+		s := value.AsString()
+		switch {
+		case uuidPattern.MatchString(s) || hexIDPattern.MatchString(s):
+			return "<id>"
+		case timestampPattern.MatchString(s):
+			return "<timestamp>"
+		}
+		return strconv.Quote(s)
+	case attribute.STRINGSLICE:
+		quoted := make([]string, 0, len(value.AsStringSlice()))
+		for _, s := range value.AsStringSlice() {
+			quoted = append(quoted, strconv.Quote(s))
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	default:
+		return value.Emit()
+	}
+}
+
+// sortedByStart returns spans ordered by start time
+func sortedByStart(spans []sdktrace.ReadOnlySpan) []sdktrace.ReadOnlySpan {
+	sorted := append([]sdktrace.ReadOnlySpan(nil), spans...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].StartTime().Before(sorted[j].StartTime())
+	})
+	return sorted
+}
@@ -0,0 +1,98 @@
+package oteltest
+
+import (
+	"strings"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Spans is a set of recorded spans
+type Spans struct {
+	tb    testing.TB
+	spans []sdktrace.ReadOnlySpan
+}
+
+// All returns the spans
+func (s Spans) All() []sdktrace.ReadOnlySpan {
+	return s.spans
+}
+
+// Len returns the number of spans
+func (s Spans) Len() int {
+	return len(s.spans)
+}
+
+// Names returns the span names
+func (s Spans) Names() []string {
+	names := make([]string, len(s.spans))
+	for i, span := range s.spans {
+		names[i] = span.Name()
+	}
+	return names
+}
+
+// Named returns the spans named name
+func (s Spans) Named(name string) Spans {
+	return s.filter(func(span sdktrace.ReadOnlySpan) bool {
+		return span.Name() == name
+	})
+}
+
+// Roots returns the spans whose parent was not recorded, usually the request
+// spans
+func (s Spans) Roots() Spans {
+	return s.filter(func(span sdktrace.ReadOnlySpan) bool {
+		return s.byID(span.Parent().SpanID()) == nil
+	})
+}
+
+// ChildrenOf returns the direct children of span
+func (s Spans) ChildrenOf(span sdktrace.ReadOnlySpan) Spans {
+	id := span.SpanContext().SpanID()
+	return s.filter(func(child sdktrace.ReadOnlySpan) bool {
+		return child.Parent().SpanID() == id && child.Parent().TraceID() == span.SpanContext().TraceID()
+	})
+}
+
+// Find returns the assertions of the first span named name, failing the test
+// when there is none
+func (s Spans) Find(name string) *SpanAssert {
+	s.tb.Helper()
+
+	named := s.Named(name)
+	if named.Len() == 0 {
+		s.tb.Fatalf("oteltest: no span named %q, recorded spans: [%s]", name, strings.Join(s.Names(), ", "))
+	}
+	return &SpanAssert{tb: s.tb, span: named.spans[0], spans: s}
+}
+
+// Assert returns the assertions of span
+func (s Spans) Assert(span sdktrace.ReadOnlySpan) *SpanAssert {
+	return &SpanAssert{tb: s.tb, span: span, spans: s}
+}
+
+// filter returns the spans keep returns true for
+func (s Spans) filter(keep func(sdktrace.ReadOnlySpan) bool) Spans {
+	var kept []sdktrace.ReadOnlySpan
+	for _, span := range s.spans {
+		if keep(span) {
+			kept = append(kept, span)
+		}
+	}
+	return Spans{tb: s.tb, spans: kept}
+}
+
+// byID returns the span with the given ID, nil when it was not recorded
+func (s Spans) byID(id trace.SpanID) sdktrace.ReadOnlySpan {
+	if !id.IsValid() {
+		return nil
+	}
+	for _, span := range s.spans {
+		if span.SpanContext().SpanID() == id {
+			return span
+		}
+	}
+	return nil
+}
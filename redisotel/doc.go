@@ -0,0 +1,9 @@
+// Package redisotel traces and measures go-redis commands. Add a Hook with
+// Instrument or client.AddHook(NewHook()): commands get Redis.<COMMAND> spans and
+// pipelines a Redis.Pipeline span, with the command name, the key count and the
+// sanitized arguments, and their latency is recorded in
+// db.client.operation.duration. GET-style commands (GET, HGET, MGET, ...) record
+// their hits and misses in db.redis.cache.requests.
+// Tracer and meter providers default to the global ones and can be set with
+// WithTracerProvider and WithMeterProvider.
+package redisotel
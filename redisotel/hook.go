@@ -0,0 +1,281 @@
+package redisotel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	tel "github.com/Doraverse-Workspace/open-observe/otel"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// cacheReadCommands are the GET-style commands whose result is a hit or a miss
+var cacheReadCommands = map[string]bool{
+	"get": true, "getex": true, "getdel": true, "hget": true, "mget": true, "hmget": true,
+}
+
+// Hook is a go-redis hook tracing commands, pipelines and dials. Every command
+// gets a Redis.<COMMAND> span and every pipeline a Redis.Pipeline span, with their
+// duration recorded in db.client.operation.duration. GET-style commands also
+// record whether they hit or missed.
+type Hook struct {
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+	requests metric.Int64Counter
+	attrs    []attribute.KeyValue
+	cfg      config
+}
+
+var _ redis.Hook = (*Hook)(nil)
+
+// NewHook creates a hook, add it with client.AddHook
+func NewHook(opts ...Option) (*Hook, error) {
+	cfg := newConfig(opts)
+	meter := cfg.meterProvider.Meter(ScopeName)
+
+	duration, err := meter.Float64Histogram(
+		"db.client.operation.duration",
+		metric.WithDescription("Duration of Redis commands and pipelines."),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.0001, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Redis duration histogram: %w", err)
+	}
+	requests, err := meter.Int64Counter(
+		"db.redis.cache.requests",
+		metric.WithDescription("The number of keys read by GET-style commands, by result (hit or miss)."),
+		metric.WithUnit("{key}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Redis cache requests counter: %w", err)
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system", "redis"),
+		attribute.Int("db.redis.database_index", cfg.dbIndex),
+	}
+	if cfg.serverAddress != "" {
+		attrs = append(attrs, attribute.String("server.address", cfg.serverAddress))
+	}
+
+	return &Hook{
+		tracer:   cfg.tracerProvider.Tracer(ScopeName),
+		duration: duration,
+		requests: requests,
+		attrs:    attrs,
+		cfg:      cfg,
+	}, nil
+}
+
+// Instrument adds a Hook to client. The server address and database index are
+// read from the client options unless set with options.
+func Instrument(client redis.UniversalClient, opts ...Option) error {
+	var clientOpts []Option
+	switch c := client.(type) {
+	case *redis.Client:
+		clientOpts = append(clientOpts, WithServerAddress(c.Options().Addr), WithDBIndex(c.Options().DB))
+	case *redis.ClusterClient:
+		clientOpts = append(clientOpts, WithServerAddress(strings.Join(c.Options().Addrs, ",")))
+	}
+
+	hook, err := NewHook(append(clientOpts, opts...)...)
+	if err != nil {
+		return err
+	}
+	client.AddHook(hook)
+	return nil
+}
+
+// DialHook traces the connections opened by the client
+func (h *Hook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		ctx, span := h.tracer.Start(ctx, "Redis.Dial",
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(h.attrs...),
+			trace.WithAttributes(
+				attribute.String("network.transport", network),
+				attribute.String("server.address", addr),
+			),
+		)
+		defer span.End()
+
+		conn, err := next(ctx, network, addr)
+		handleError(span, err)
+		return conn, err
+	}
+}
+
+// ProcessHook traces a command
+func (h *Hook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		name := cmd.Name()
+		operation := strings.ToUpper(cmd.FullName())
+
+		attrs := append([]attribute.KeyValue{
+			attribute.String("db.operation", operation),
+			attribute.Int("db.redis.key_count", len(keyPositions(name, cmd.Args()))),
+		}, h.attrs...)
+		if !h.cfg.disableStatements {
+			attrs = append(attrs, attribute.String("db.statement",
+				sanitizeCommand(name, cmd.Args(), h.cfg.redactKeys, h.cfg.maxStatementLength)))
+		}
+
+		ctx, span := h.tracer.Start(ctx, "Redis."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attrs...),
+		)
+		defer span.End()
+		start := time.Now()
+
+		err := next(ctx, cmd)
+		h.recordDuration(ctx, operation, start)
+		// go-redis sets the command error after the hooks return
+		h.recordCacheResult(ctx, span, cmd, err)
+		handleError(span, err)
+		return err
+	}
+}
+
+// ProcessPipelineHook traces a pipeline, or a transaction when it is wrapped in
+// MULTI and EXEC
+func (h *Hook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		names := make([]string, 0, len(cmds))
+		keyCount := 0
+		transaction := false
+		for _, cmd := range cmds {
+			name := cmd.Name()
+			if name == "multi" {
+				transaction = true
+			}
+			names = append(names, strings.ToUpper(cmd.FullName()))
+			keyCount += len(keyPositions(name, cmd.Args()))
+		}
+
+		attrs := append([]attribute.KeyValue{
+			attribute.String("db.operation", "PIPELINE"),
+			attribute.Int("db.redis.pipeline_length", len(cmds)),
+			attribute.Int("db.redis.key_count", keyCount),
+			attribute.Bool("db.redis.transaction", transaction),
+			attribute.StringSlice("db.redis.commands", names),
+		}, h.attrs...)
+		if !h.cfg.disableStatements {
+			statements := make([]string, len(cmds))
+			for i, cmd := range cmds {
+				statements[i] = sanitizeCommand(cmd.Name(), cmd.Args(), h.cfg.redactKeys, h.cfg.maxStatementLength)
+			}
+			statement := strings.Join(statements, "\n")
+			if len(statement) > h.cfg.maxStatementLength {
+				statement = statement[:h.cfg.maxStatementLength] + "..."
+			}
+			attrs = append(attrs, attribute.String("db.statement", statement))
+		}
+
+		ctx, span := h.tracer.Start(ctx, "Redis.Pipeline",
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attrs...),
+		)
+		defer span.End()
+		start := time.Now()
+
+		err := next(ctx, cmds)
+		h.recordDuration(ctx, "PIPELINE", start)
+		for _, cmd := range cmds {
+			h.recordCacheResult(ctx, span, cmd, cmd.Err())
+		}
+		handleError(span, firstError(cmds, err))
+		return err
+	}
+}
+
+// recordDuration records the duration of a command or pipeline
+func (h *Hook) recordDuration(ctx context.Context, operation string, start time.Time) {
+	h.duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+		attribute.String("db.system", "redis"),
+		attribute.String("db.operation", operation),
+	))
+}
+
+// recordCacheResult adds the hits and misses of a GET-style command to the span
+// and to db.redis.cache.requests
+func (h *Hook) recordCacheResult(ctx context.Context, span trace.Span, cmd redis.Cmder, err error) {
+	if !cacheReadCommands[cmd.Name()] {
+		return
+	}
+
+	var hits, misses int
+	switch c := cmd.(type) {
+	case *redis.SliceCmd:
+		// MGET and HMGET return nil for every missing key
+		if err != nil {
+			return
+		}
+		for _, value := range c.Val() {
+			if value == nil {
+				misses++
+			} else {
+				hits++
+			}
+		}
+	default:
+		switch {
+		case errors.Is(err, redis.Nil):
+			misses = 1
+		case err == nil:
+			hits = 1
+		default:
+			return
+		}
+	}
+
+	if _, multi := cmd.(*redis.SliceCmd); !multi {
+		span.SetAttributes(attribute.Bool("db.redis.cache_hit", hits > 0))
+	}
+	operation := attribute.String("db.operation", strings.ToUpper(cmd.Name()))
+	span.AddEvent("cache.result", trace.WithAttributes(
+		operation,
+		attribute.Int("db.redis.cache.hits", hits),
+		attribute.Int("db.redis.cache.misses", misses),
+	))
+
+	system := attribute.String("db.system", "redis")
+	if hits > 0 {
+		h.requests.Add(ctx, int64(hits), metric.WithAttributes(system, operation, attribute.String("result", "hit")))
+	}
+	if misses > 0 {
+		h.requests.Add(ctx, int64(misses), metric.WithAttributes(system, operation, attribute.String("result", "miss")))
+	}
+}
+
+// firstError returns the first error of the commands of a pipeline, a missing
+// key (redis.Nil) is not an error
+func firstError(cmds []redis.Cmder, err error) error {
+	for _, cmd := range cmds {
+		if cmdErr := cmd.Err(); cmdErr != nil && !errors.Is(cmdErr, redis.Nil) {
+			return cmdErr
+		}
+	}
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	return err
+}
+
+// handleError handles error and sets span status. redis.Nil, a missing key, and
+// errors the registered ErrorClassifier ignores leave the span successful.
+func handleError(span trace.Span, err error) {
+	if err == nil || errors.Is(err, redis.Nil) || tel.ClassifyError(err, 0) == tel.ErrorClassIgnore {
+		span.SetStatus(codes.Ok, "")
+		return
+	}
+	span.SetStatus(codes.Error, err.Error())
+	span.RecordError(err)
+}
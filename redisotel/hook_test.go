@@ -0,0 +1,195 @@
+package redisotel_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Doraverse-Workspace/open-observe/oteltest"
+	"github.com/Doraverse-Workspace/open-observe/redisotel"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newClient returns a client of a miniredis server, instrumented into a new
+// recorder and metric reader
+func newClient(t *testing.T, opts ...redisotel.Option) (*redis.Client, *oteltest.Recorder, *sdkmetric.ManualReader) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+
+	r := oteltest.NewLocal(t)
+	reader := sdkmetric.NewManualReader()
+	opts = append([]redisotel.Option{
+		redisotel.WithTracerProvider(r.TracerProvider),
+		redisotel.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	}, opts...)
+	if err := redisotel.Instrument(client, opts...); err != nil {
+		t.Fatal(err)
+	}
+	return client, r, reader
+}
+
+// cacheRequests returns db.redis.cache.requests by operation and result
+func cacheRequests(t *testing.T, reader sdkmetric.Reader) map[[2]string]int64 {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	requests := map[[2]string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "db.redis.cache.requests" {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				operation, _ := dp.Attributes.Value("db.operation")
+				result, _ := dp.Attributes.Value("result")
+				requests[[2]string{operation.AsString(), result.AsString()}] += dp.Value
+			}
+		}
+	}
+	return requests
+}
+
+func TestProcessHookSpans(t *testing.T) {
+	client, r, _ := newClient(t)
+	ctx := context.Background()
+	// The first command dials
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Fatal(err)
+	}
+	r.Reset()
+
+	ctx, parent := r.Tracer("test").Start(ctx, "parent")
+	if err := client.Set(ctx, "session:42", "secret-token", 0).Err(); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.LPush(ctx, "session:42", "x").Err(); err == nil {
+		t.Fatal("LPUSH on a string succeeded, want WRONGTYPE")
+	}
+	parent.End()
+
+	spans := r.Spans()
+	spans.Find("Redis.SET").
+		HasParent("parent").
+		HasKind(trace.SpanKindClient).
+		HasAttribute("db.system", "redis").
+		HasAttribute("db.operation", "SET").
+		HasAttribute("db.statement", "set session:42 ?").
+		HasAttribute("db.redis.key_count", 1).
+		HasAttribute("db.redis.database_index", 0).
+		HasAttributeKey("server.address").
+		HasStatus(codes.Ok)
+	spans.Find("Redis.LPUSH").
+		HasStatus(codes.Error).
+		HasEvent("exception")
+}
+
+func TestProcessHookRedactedKeys(t *testing.T) {
+	client, r, _ := newClient(t, redisotel.WithRedactedKeys())
+
+	if err := client.Set(context.Background(), "session:42", "secret-token", 0).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	r.Find("Redis.SET").HasAttribute("db.statement", "set ? ?")
+}
+
+func TestDialHook(t *testing.T) {
+	client, r, _ := newClient(t)
+
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	r.Find("Redis.Dial").
+		HasAttribute("network.transport", "tcp").
+		HasStatus(codes.Ok)
+}
+
+func TestCacheHitsAndMisses(t *testing.T) {
+	client, r, reader := newClient(t)
+	ctx := context.Background()
+	if err := client.Set(ctx, "user:1", "ada", 0).Err(); err != nil {
+		t.Fatal(err)
+	}
+	r.Reset()
+
+	if err := client.Get(ctx, "user:1").Err(); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Get(ctx, "user:2").Err(); !errors.Is(err, redis.Nil) {
+		t.Fatalf("GET user:2 error = %v, want redis.Nil", err)
+	}
+	if err := client.MGet(ctx, "user:1", "user:2", "user:3").Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	gets := r.Spans().Named("Redis.GET").All()
+	if len(gets) != 2 {
+		t.Fatalf("%d Redis.GET spans, want 2", len(gets))
+	}
+	r.Spans().Assert(gets[0]).
+		HasAttribute("db.redis.cache_hit", true).
+		HasEvent("cache.result", attribute.Int("db.redis.cache.hits", 1), attribute.Int("db.redis.cache.misses", 0))
+	// A missing key is not an error
+	r.Spans().Assert(gets[1]).
+		HasAttribute("db.redis.cache_hit", false).
+		HasEvent("cache.result", attribute.Int("db.redis.cache.hits", 0), attribute.Int("db.redis.cache.misses", 1)).
+		HasStatus(codes.Ok)
+	r.Find("Redis.MGET").
+		HasAttribute("db.redis.key_count", 3).
+		HasEvent("cache.result", attribute.Int("db.redis.cache.hits", 1), attribute.Int("db.redis.cache.misses", 2))
+
+	want := map[[2]string]int64{
+		{"GET", "hit"}:   1,
+		{"GET", "miss"}:  1,
+		{"MGET", "hit"}:  1,
+		{"MGET", "miss"}: 2,
+	}
+	if got := cacheRequests(t, reader); !reflect.DeepEqual(got, want) {
+		t.Errorf("db.redis.cache.requests = %v, want %v", got, want)
+	}
+}
+
+func TestProcessPipelineHook(t *testing.T) {
+	client, r, reader := newClient(t)
+	ctx := context.Background()
+	if err := client.Set(ctx, "user:1", "ada", 0).Err(); err != nil {
+		t.Fatal(err)
+	}
+	r.Reset()
+
+	_, err := client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Get(ctx, "user:1")
+		pipe.Get(ctx, "user:2")
+		pipe.Incr(ctx, "counter")
+		return nil
+	})
+	if !errors.Is(err, redis.Nil) {
+		t.Fatalf("TxPipelined() error = %v, want redis.Nil of the missing key", err)
+	}
+
+	r.Find("Redis.Pipeline").
+		HasAttribute("db.operation", "PIPELINE").
+		HasAttribute("db.redis.transaction", true).
+		HasAttribute("db.redis.commands", []string{"MULTI", "GET", "GET", "INCR", "EXEC"}).
+		HasAttribute("db.statement", "multi\nget user:1\nget user:2\nincr counter\nexec").
+		HasStatus(codes.Ok)
+	if got := cacheRequests(t, reader); got[[2]string{"GET", "hit"}] != 1 || got[[2]string{"GET", "miss"}] != 1 {
+		t.Errorf("db.redis.cache.requests = %v, want one GET hit and one GET miss", got)
+	}
+}
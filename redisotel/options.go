@@ -0,0 +1,92 @@
+package redisotel
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the spans and metrics of the package
+const ScopeName = "redis"
+
+// defaultMaxStatementLength is used when WithMaxStatementLength is not set
+const defaultMaxStatementLength = 1024
+
+// Option configures a Hook
+type Option func(*config)
+
+type config struct {
+	tracerProvider     trace.TracerProvider
+	meterProvider      metric.MeterProvider
+	serverAddress      string
+	dbIndex            int
+	maxStatementLength int
+	disableStatements  bool
+	redactKeys         bool
+}
+
+// WithTracerProvider sets the tracer provider of the spans, the global provider
+// is used by default
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider sets the meter provider of the metrics, the global provider
+// is used by default
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = provider
+	}
+}
+
+// WithServerAddress sets server.address, Instrument reads it from the client
+func WithServerAddress(address string) Option {
+	return func(c *config) {
+		c.serverAddress = address
+	}
+}
+
+// WithDBIndex sets db.redis.database_index, Instrument reads it from the client
+func WithDBIndex(index int) Option {
+	return func(c *config) {
+		c.dbIndex = index
+	}
+}
+
+// WithMaxStatementLength sets the maximum length of db.statement, longer
+// statements are truncated. The default is 1024.
+func WithMaxStatementLength(length int) Option {
+	return func(c *config) {
+		c.maxStatementLength = length
+	}
+}
+
+// WithoutStatements stops recording db.statement
+func WithoutStatements() Option {
+	return func(c *config) {
+		c.disableStatements = true
+	}
+}
+
+// WithRedactedKeys replaces the keys in db.statement with "?" as well, for keys
+// holding personal data such as "session:<email>"
+func WithRedactedKeys() Option {
+	return func(c *config) {
+		c.redactKeys = true
+	}
+}
+
+// newConfig applies opts over the defaults
+func newConfig(opts []Option) config {
+	c := config{
+		tracerProvider:     otel.GetTracerProvider(),
+		meterProvider:      otel.GetMeterProvider(),
+		maxStatementLength: defaultMaxStatementLength,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
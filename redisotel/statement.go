@@ -0,0 +1,100 @@
+package redisotel
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// noKeyCommands take no key
+var noKeyCommands = map[string]bool{
+	"auth": true, "client": true, "cluster": true, "command": true, "config": true,
+	"dbsize": true, "discard": true, "echo": true, "exec": true, "flushall": true,
+	"flushdb": true, "hello": true, "info": true, "multi": true, "ping": true,
+	"psubscribe": true, "publish": true, "punsubscribe": true, "quit": true,
+	"script": true, "select": true, "subscribe": true, "time": true, "unsubscribe": true,
+	"unwatch": true,
+}
+
+// allKeyCommands take only keys as arguments
+var allKeyCommands = map[string]bool{
+	"del": true, "exists": true, "mget": true, "pfcount": true, "sdiff": true,
+	"sinter": true, "sunion": true, "touch": true, "unlink": true, "watch": true,
+}
+
+// pairCommands take key value pairs
+var pairCommands = map[string]bool{
+	"mset": true, "msetnx": true,
+}
+
+// scriptCommands take the number of keys as their second argument
+var scriptCommands = map[string]bool{
+	"eval": true, "eval_ro": true, "evalsha": true, "evalsha_ro": true, "fcall": true, "fcall_ro": true,
+}
+
+// keyPositions returns the positions of the keys among the arguments of a
+// command, args[0] being the command name
+func keyPositions(name string, args []interface{}) []int {
+	var positions []int
+	switch {
+	case len(args) < 2 || noKeyCommands[name]:
+		return nil
+	case allKeyCommands[name]:
+		for i := 1; i < len(args); i++ {
+			positions = append(positions, i)
+		}
+	case pairCommands[name]:
+		for i := 1; i < len(args); i += 2 {
+			positions = append(positions, i)
+		}
+	case scriptCommands[name]:
+		if len(args) < 3 {
+			return nil
+		}
+		numKeys, err := strconv.Atoi(fmt.Sprint(args[2]))
+		if err != nil {
+			return nil
+		}
+		for i := 3; i < len(args) && i < 3+numKeys; i++ {
+			positions = append(positions, i)
+		}
+	default:
+		positions = []int{1}
+	}
+	return positions
+}
+
+// sanitizeCommand returns the command with its values replaced by "?". Keys are
+// kept unless redactKeys is set.
+func sanitizeCommand(name string, args []interface{}, redactKeys bool, maxLength int) string {
+	keys := map[int]bool{}
+	if !redactKeys {
+		for _, position := range keyPositions(name, args) {
+			keys[position] = true
+		}
+	}
+
+	var sb strings.Builder
+	for i, arg := range args {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		switch {
+		case i == 0:
+			sb.WriteString(fmt.Sprint(arg))
+		case keys[i]:
+			sb.WriteString(fmt.Sprint(arg))
+		default:
+			sb.WriteByte('?')
+		}
+		if sb.Len() > maxLength {
+			break
+		}
+	}
+
+	statement := sb.String()
+	if len(statement) > maxLength {
+		statement = statement[:maxLength] + "..."
+	}
+	return statement
+}
@@ -0,0 +1,52 @@
+package redisotel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKeyPositions(t *testing.T) {
+	tests := []struct {
+		args []interface{}
+		want []int
+	}{
+		{args: []interface{}{"get", "user:1"}, want: []int{1}},
+		{args: []interface{}{"set", "user:1", "ada", "ex", 60}, want: []int{1}},
+		{args: []interface{}{"del", "a", "b", "c"}, want: []int{1, 2, 3}},
+		{args: []interface{}{"mset", "a", 1, "b", 2}, want: []int{1, 3}},
+		{args: []interface{}{"eval", "return 1", 2, "a", "b", "arg"}, want: []int{3, 4}},
+		{args: []interface{}{"evalsha", "abc", "x"}, want: nil},
+		{args: []interface{}{"ping"}, want: nil},
+		{args: []interface{}{"publish", "channel", "message"}, want: nil},
+	}
+
+	for _, tt := range tests {
+		name := tt.args[0].(string)
+		if got := keyPositions(name, tt.args); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("keyPositions(%v) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestSanitizeCommand(t *testing.T) {
+	tests := []struct {
+		args       []interface{}
+		redactKeys bool
+		maxLength  int
+		want       string
+	}{
+		{args: []interface{}{"set", "session:42", "secret-token", "ex", 60}, maxLength: 100, want: "set session:42 ? ? ?"},
+		{args: []interface{}{"set", "session:42", "secret-token"}, redactKeys: true, maxLength: 100, want: "set ? ?"},
+		{args: []interface{}{"mset", "a", "1", "b", "2"}, maxLength: 100, want: "mset a ? b ?"},
+		{args: []interface{}{"eval", "return redis.call('get', KEYS[1])", 1, "user:1", "arg"}, maxLength: 100, want: "eval ? ? user:1 ?"},
+		{args: []interface{}{"auth", "user", "password"}, maxLength: 100, want: "auth ? ?"},
+		{args: []interface{}{"del", "aaaaaaaaaa", "bbbbbbbbbb", "cccccccccc"}, maxLength: 10, want: "del aaaaaa..."},
+	}
+
+	for _, tt := range tests {
+		name := tt.args[0].(string)
+		if got := sanitizeCommand(name, tt.args, tt.redactKeys, tt.maxLength); got != tt.want {
+			t.Errorf("sanitizeCommand(%v, %v) = %q, want %q", tt.args, tt.redactKeys, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+package sqlotel
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// otelConn wraps a driver.Conn. It implements every optional connection
+// interface and falls back like database/sql when the wrapped connection does not.
+type otelConn struct {
+	conn driver.Conn
+	in   *instrumentation
+}
+
+var (
+	_ driver.Conn               = (*otelConn)(nil)
+	_ driver.ConnBeginTx        = (*otelConn)(nil)
+	_ driver.ConnPrepareContext = (*otelConn)(nil)
+	_ driver.ExecerContext      = (*otelConn)(nil)
+	_ driver.QueryerContext     = (*otelConn)(nil)
+	_ driver.Pinger             = (*otelConn)(nil)
+	_ driver.SessionResetter    = (*otelConn)(nil)
+	_ driver.Validator          = (*otelConn)(nil)
+	_ driver.NamedValueChecker  = (*otelConn)(nil)
+)
+
+// Prepare prepares a traced statement
+func (c *otelConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext prepares a traced statement
+func (c *otelConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	op := c.in.start(ctx, "Prepare", query)
+
+	var stmt driver.Stmt
+	var err error
+	if cp, ok := c.conn.(driver.ConnPrepareContext); ok {
+		stmt, err = cp.PrepareContext(op.ctx, query)
+	} else {
+		stmt, err = c.conn.Prepare(query)
+	}
+	c.in.end(op, err)
+	if err != nil {
+		return nil, err
+	}
+	return &otelStmt{stmt: stmt, conn: c.conn, query: query, in: c.in}, nil
+}
+
+// Close closes the connection
+func (c *otelConn) Close() error {
+	return c.conn.Close()
+}
+
+// Begin starts a traced transaction
+//
+// Deprecated: database/sql calls BeginTx
+func (c *otelConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx starts a traced transaction
+func (c *otelConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	op := c.in.start(ctx, "Begin", "")
+	if opts.ReadOnly {
+		op.span.SetAttributes(attribute.Bool("db.sql.read_only", true))
+	}
+
+	var tx driver.Tx
+	var err error
+	if cb, ok := c.conn.(driver.ConnBeginTx); ok {
+		tx, err = cb.BeginTx(op.ctx, opts)
+	} else if opts.Isolation != 0 || opts.ReadOnly {
+		err = errors.New("sqlotel: driver does not support non-default isolation levels or read-only transactions")
+	} else {
+		tx, err = c.conn.Begin()
+	}
+	c.in.end(op, err)
+	if err != nil {
+		return nil, err
+	}
+	return &otelTx{tx: tx, ctx: ctx, in: c.in}, nil
+}
+
+// ExecContext runs a traced statement without preparing it. Drivers without
+// ExecerContext get driver.ErrSkip, database/sql then prepares the statement.
+func (c *otelConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	// A call answered with driver.ErrSkip did not happen: database/sql retries it
+	// with a prepared statement, which is traced on its own. The span is started
+	// once the call returned so it is never left open, the driver gets the context
+	// of the caller.
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	if errors.Is(err, driver.ErrSkip) {
+		return nil, err
+	}
+	op := c.in.startAt(ctx, "Exec", query, start)
+	setRowsAffected(op, result, err)
+	c.in.end(op, err)
+	return result, err
+}
+
+// QueryContext runs a traced query without preparing it. Drivers without
+// QueryerContext get driver.ErrSkip, database/sql then prepares the statement.
+func (c *otelConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	// The span is started once the call returned, see ExecContext
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if errors.Is(err, driver.ErrSkip) {
+		return nil, err
+	}
+	op := c.in.startAt(ctx, "Query", query, start)
+	c.in.end(op, err)
+	return rows, err
+}
+
+// Ping checks the connection, it is not traced
+func (c *otelConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession resets the connection before it is reused
+func (c *otelConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+// IsValid reports whether the connection can be reused
+func (c *otelConn) IsValid() bool {
+	if validator, ok := c.conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// CheckNamedValue converts arguments with the driver's own checker
+func (c *otelConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+// otelStmt wraps a prepared driver.Stmt
+type otelStmt struct {
+	stmt driver.Stmt
+	// conn is the wrapped connection the statement was prepared on
+	conn  driver.Conn
+	query string
+	in    *instrumentation
+}
+
+var (
+	_ driver.Stmt              = (*otelStmt)(nil)
+	_ driver.StmtExecContext   = (*otelStmt)(nil)
+	_ driver.StmtQueryContext  = (*otelStmt)(nil)
+	_ driver.NamedValueChecker = (*otelStmt)(nil)
+	_ driver.ColumnConverter   = (*otelStmt)(nil)
+)
+
+// Close closes the statement
+func (s *otelStmt) Close() error {
+	return s.stmt.Close()
+}
+
+// NumInput returns the number of placeholders of the statement
+func (s *otelStmt) NumInput() int {
+	return s.stmt.NumInput()
+}
+
+// Exec runs the traced statement
+//
+// Deprecated: database/sql calls ExecContext
+func (s *otelStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), valuesToNamedValues(args))
+}
+
+// Query runs the traced query
+//
+// Deprecated: database/sql calls QueryContext
+func (s *otelStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), valuesToNamedValues(args))
+}
+
+// ExecContext runs the traced statement
+func (s *otelStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	op := s.in.start(ctx, "Exec", s.query)
+
+	var result driver.Result
+	var err error
+	if se, ok := s.stmt.(driver.StmtExecContext); ok {
+		result, err = se.ExecContext(op.ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValuesToValues(args); err == nil {
+			result, err = s.stmt.Exec(values)
+		}
+	}
+	setRowsAffected(op, result, err)
+	s.in.end(op, err)
+	return result, err
+}
+
+// QueryContext runs the traced query
+func (s *otelStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	op := s.in.start(ctx, "Query", s.query)
+
+	var rows driver.Rows
+	var err error
+	if sq, ok := s.stmt.(driver.StmtQueryContext); ok {
+		rows, err = sq.QueryContext(op.ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValuesToValues(args); err == nil {
+			rows, err = s.stmt.Query(values)
+		}
+	}
+	s.in.end(op, err)
+	return rows, err
+}
+
+// CheckNamedValue converts arguments with the checker of the statement, or of its
+// connection. database/sql only asks the connection when the statement has none,
+// so the wrapper asks it on the statement's behalf.
+func (s *otelStmt) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := s.stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	if checker, ok := s.conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+// ColumnConverter returns the converter of the statement's arguments
+func (s *otelStmt) ColumnConverter(idx int) driver.ValueConverter {
+	if converter, ok := s.stmt.(driver.ColumnConverter); ok {
+		return converter.ColumnConverter(idx)
+	}
+	return driver.DefaultParameterConverter
+}
+
+// otelTx wraps a driver.Tx
+type otelTx struct {
+	tx driver.Tx
+	// ctx is the context the transaction was started with, Commit and Rollback
+	// have none of their own
+	ctx context.Context
+	in  *instrumentation
+}
+
+// Commit commits the traced transaction
+func (t *otelTx) Commit() error {
+	op := t.in.start(t.ctx, "Commit", "")
+	err := t.tx.Commit()
+	t.in.end(op, err)
+	return err
+}
+
+// Rollback rolls back the traced transaction
+func (t *otelTx) Rollback() error {
+	op := t.in.start(t.ctx, "Rollback", "")
+	err := t.tx.Rollback()
+	t.in.end(op, err)
+	return err
+}
+
+// setRowsAffected adds the rows affected by a statement to its span
+func setRowsAffected(op *operation, result driver.Result, err error) {
+	if err != nil || result == nil {
+		return
+	}
+	if rows, err := result.RowsAffected(); err == nil {
+		op.span.SetAttributes(attribute.Int64("db.sql.rows_affected", rows))
+	}
+}
+
+// valuesToNamedValues converts positional arguments to named values
+func valuesToNamedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return named
+}
+
+// namedValuesToValues converts named values to positional arguments for legacy
+// drivers, which do not support names
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("sqlotel: driver does not support the use of Named Parameters")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
@@ -0,0 +1,7 @@
+// Package sqlotel traces and measures database/sql operations. Open a database
+// with Open or OpenDB, or register a driver wrapped with WrapDriver: Query, Exec,
+// Prepare, Begin, Commit and Rollback get SQL.<method> spans with the sanitized
+// statement, and RecordStats reports the connection pool statistics as metrics.
+// Tracer and meter providers default to the global ones and can be set with
+// WithTracerProvider and WithMeterProvider.
+package sqlotel
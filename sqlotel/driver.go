@@ -0,0 +1,226 @@
+package sqlotel
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"log/slog"
+	"time"
+
+	tel "github.com/Doraverse-Workspace/open-observe/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Open opens a database like sql.Open, with every query, statement and transaction
+// traced. db.system is derived from driverName unless WithDBSystem is set.
+func Open(driverName, dataSourceName string, opts ...Option) (*sql.DB, error) {
+	db, err := sql.Open(driverName, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	d := db.Driver()
+	_ = db.Close()
+
+	opts = append([]Option{WithDBSystem(systemFromDriverName(driverName))}, opts...)
+	connector, err := WrapDriver(d, opts...).(driver.DriverContext).OpenConnector(dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(connector), nil
+}
+
+// OpenDB opens a database like sql.OpenDB, with every query, statement and
+// transaction traced
+func OpenDB(connector driver.Connector, opts ...Option) *sql.DB {
+	return sql.OpenDB(WrapConnector(connector, opts...))
+}
+
+// WrapDriver returns a traced driver, e.g. to register it under a new name:
+//
+//	sql.Register("postgres-otel", sqlotel.WrapDriver(&pq.Driver{}, sqlotel.WithDBSystem("postgresql")))
+func WrapDriver(d driver.Driver, opts ...Option) driver.Driver {
+	return &otelDriver{driver: d, in: newInstrumentation(opts)}
+}
+
+// WrapConnector returns a traced connector
+func WrapConnector(connector driver.Connector, opts ...Option) driver.Connector {
+	in := newInstrumentation(opts)
+	return &otelConnector{
+		connector: connector,
+		driver:    &otelDriver{driver: connector.Driver(), in: in},
+		in:        in,
+	}
+}
+
+// instrumentation holds the tracer, instruments and settings shared by the
+// wrappers of a driver
+type instrumentation struct {
+	tracer             trace.Tracer
+	duration           metric.Float64Histogram
+	system             string
+	name               string
+	maxStatementLength int
+	disableStatements  bool
+}
+
+// newInstrumentation creates the tracer and instruments of the options
+func newInstrumentation(opts []Option) *instrumentation {
+	cfg := newConfig(opts)
+
+	duration, err := cfg.meterProvider.Meter(ScopeName).Float64Histogram(
+		"db.client.operation.duration",
+		metric.WithDescription("Duration of database client operations."),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10),
+	)
+	if err != nil {
+		slog.Error("Failed to create db.client.operation.duration histogram, SQL durations are not recorded", slog.Any("error", err))
+	}
+
+	return &instrumentation{
+		tracer:             cfg.tracerProvider.Tracer(ScopeName),
+		duration:           duration,
+		system:             cfg.system,
+		name:               cfg.name,
+		maxStatementLength: cfg.maxStatementLength,
+		disableStatements:  cfg.disableStatements,
+	}
+}
+
+// operation is a traced driver call
+type operation struct {
+	ctx       context.Context
+	span      trace.Span
+	start     time.Time
+	method    string
+	operation string
+}
+
+// start starts the SQL.<method> span of a driver call, query is empty for calls
+// without statement such as Begin
+func (in *instrumentation) start(ctx context.Context, method, query string) *operation {
+	return in.startAt(ctx, method, query, time.Now())
+}
+
+// startAt starts the span of a driver call that began at start
+func (in *instrumentation) startAt(ctx context.Context, method, query string, start time.Time) *operation {
+	op := &operation{start: start, method: method, operation: method}
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system", in.system),
+		attribute.String("db.sql.method", method),
+	}
+	if in.name != "" {
+		attrs = append(attrs, attribute.String("db.name", in.name))
+	}
+	if query != "" {
+		statement := SanitizeQueryDialect(query, in.maxStatementLength, dialectFromSystem(in.system))
+		if statement.Operation != "" {
+			op.operation = statement.Operation
+		}
+		attrs = append(attrs, attribute.String("db.query.shape_hash", statement.ShapeHash))
+		if !in.disableStatements {
+			attrs = append(attrs, attribute.String("db.statement", statement.Text))
+		}
+	}
+	attrs = append(attrs, attribute.String("db.operation", op.operation))
+
+	op.ctx, op.span = in.tracer.Start(ctx, fmt.Sprintf("SQL.%s", method),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+		trace.WithTimestamp(start),
+	)
+	return op
+}
+
+// end ends the span of the call and records its duration
+func (in *instrumentation) end(op *operation, err error) {
+	if in.duration != nil {
+		in.duration.Record(op.ctx, time.Since(op.start).Seconds(), metric.WithAttributes(
+			attribute.String("db.system", in.system),
+			attribute.String("db.operation", op.operation),
+		))
+	}
+
+	handleError(op.span, err)
+	op.span.End()
+}
+
+// handleError handles error and sets span status. Errors the registered
+// ErrorClassifier ignores, such as context.Canceled, leave the span successful.
+func handleError(span trace.Span, err error) {
+	if err == nil || tel.ClassifyError(err, 0) == tel.ErrorClassIgnore {
+		span.SetStatus(codes.Ok, "")
+		return
+	}
+	span.SetStatus(codes.Error, err.Error())
+	span.RecordError(err)
+}
+
+// otelDriver wraps a driver.Driver
+type otelDriver struct {
+	driver driver.Driver
+	in     *instrumentation
+}
+
+// Open opens a traced connection
+func (d *otelDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &otelConn{conn: conn, in: d.in}, nil
+}
+
+// OpenConnector returns a traced connector, drivers without connector support
+// open their connections with Open
+func (d *otelDriver) OpenConnector(name string) (driver.Connector, error) {
+	if dc, ok := d.driver.(driver.DriverContext); ok {
+		connector, err := dc.OpenConnector(name)
+		if err != nil {
+			return nil, err
+		}
+		return &otelConnector{connector: connector, driver: d, in: d.in}, nil
+	}
+	return &dsnConnector{name: name, driver: d}, nil
+}
+
+// otelConnector wraps a driver.Connector
+type otelConnector struct {
+	connector driver.Connector
+	driver    *otelDriver
+	in        *instrumentation
+}
+
+// Connect opens a traced connection
+func (c *otelConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &otelConn{conn: conn, in: c.in}, nil
+}
+
+// Driver returns the traced driver
+func (c *otelConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// dsnConnector is the connector of a driver without connector support
+type dsnConnector struct {
+	name   string
+	driver *otelDriver
+}
+
+// Connect opens a traced connection
+func (c *dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.name)
+}
+
+// Driver returns the traced driver
+func (c *dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
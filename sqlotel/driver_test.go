@@ -0,0 +1,273 @@
+package sqlotel_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Doraverse-Workspace/open-observe/oteltest"
+	"github.com/Doraverse-Workspace/open-observe/sqlotel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func init() {
+	sql.Register("sqlotel-fake", fakeDriver{})
+}
+
+// errFake is returned for the queries containing "fail"
+var errFake = errors.New("fake: query failed")
+
+// fakeDriver opens fakeConns, legacyConns without ExecerContext and
+// QueryerContext when the data source name is "legacy", or skipConns when it is
+// "skip"
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	switch name {
+	case "legacy":
+		return &legacyConn{}, nil
+	case "skip":
+		return &skipConn{}, nil
+	}
+	return &fakeConn{}, nil
+}
+
+// legacyConn only prepares statements
+type legacyConn struct{}
+
+func (c *legacyConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{query: query}, nil
+}
+
+func (c *legacyConn) Close() error {
+	return nil
+}
+
+func (c *legacyConn) Begin() (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+// fakeConn runs queries without preparing them
+type fakeConn struct {
+	legacyConn
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	return (&fakeStmt{query: query}).Exec(nil)
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	return (&fakeStmt{query: query}).Query(nil)
+}
+
+// skipConn answers driver.ErrSkip to unprepared queries, as drivers do for the
+// queries they cannot run without preparing them
+type skipConn struct {
+	legacyConn
+}
+
+func (c *skipConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return nil, driver.ErrSkip
+}
+
+func (c *skipConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return nil, driver.ErrSkip
+}
+
+type fakeStmt struct {
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	if strings.Contains(s.query, "fail") {
+		return nil, errFake
+	}
+	return driver.RowsAffected(2), nil
+}
+
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	if strings.Contains(s.query, "fail") {
+		return nil, errFake
+	}
+	return &fakeRows{}, nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error {
+	return nil
+}
+
+func (fakeTx) Rollback() error {
+	return nil
+}
+
+// fakeRows returns a single row with the id 1
+type fakeRows struct {
+	done bool
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"id"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
+
+// openFake opens the fake driver traced into a new recorder
+func openFake(t *testing.T, dsn string, opts ...sqlotel.Option) (*sql.DB, *oteltest.Recorder) {
+	t.Helper()
+
+	r := oteltest.NewLocal(t)
+	db, err := sqlotel.Open("sqlotel-fake", dsn, append([]sqlotel.Option{sqlotel.WithTracerProvider(r.TracerProvider)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	return db, r
+}
+
+func TestQuerySpan(t *testing.T) {
+	db, r := openFake(t, "", sqlotel.WithDBName("app"))
+
+	ctx, parent := r.Tracer("test").Start(context.Background(), "parent")
+	var id int
+	if err := db.QueryRowContext(ctx, "SELECT id FROM users WHERE email = 'ada@example.com'").Scan(&id); err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+
+	r.Find("SQL.Query").
+		HasParent("parent").
+		HasKind(trace.SpanKindClient).
+		HasAttribute("db.system", "other_sql").
+		HasAttribute("db.name", "app").
+		HasAttribute("db.sql.method", "Query").
+		HasAttribute("db.operation", "SELECT").
+		HasAttribute("db.statement", "SELECT id FROM users WHERE email = ?").
+		HasAttributeKey("db.query.shape_hash").
+		HasStatus(codes.Ok)
+}
+
+func TestExecSpan(t *testing.T) {
+	db, r := openFake(t, "")
+
+	if _, err := db.ExecContext(context.Background(), "UPDATE users SET name = 'ada'"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(context.Background(), "DELETE FROM fail"); !errors.Is(err, errFake) {
+		t.Fatalf("ExecContext() error = %v, want %v", err, errFake)
+	}
+
+	spans := r.Spans().Named("SQL.Exec")
+	if spans.Len() != 2 {
+		t.Fatalf("%d SQL.Exec spans, want 2", spans.Len())
+	}
+	spans.Assert(spans.All()[0]).
+		HasAttribute("db.operation", "UPDATE").
+		HasAttribute("db.sql.rows_affected", 2).
+		HasStatus(codes.Ok)
+	spans.Assert(spans.All()[1]).
+		HasAttribute("db.operation", "DELETE").
+		HasStatus(codes.Error, errFake.Error()).
+		HasEvent("exception")
+}
+
+func TestPreparedFallback(t *testing.T) {
+	db, r := openFake(t, "legacy")
+
+	if _, err := db.ExecContext(context.Background(), "INSERT INTO users (name) VALUES ('ada')"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The skipped direct Exec has no span, the prepared statement has two
+	spans := r.Spans()
+	if got := spans.Names(); len(got) != 2 || got[0] != "SQL.Prepare" || got[1] != "SQL.Exec" {
+		t.Errorf("spans = %v, want [SQL.Prepare SQL.Exec]", got)
+	}
+	r.Find("SQL.Exec").HasAttribute("db.statement", "INSERT INTO users (name) VALUES (?)")
+}
+
+func TestSkippedCallHasNoSpan(t *testing.T) {
+	db, r := openFake(t, "skip")
+	started := tracetest.NewSpanRecorder()
+	r.TracerProvider.RegisterSpanProcessor(started)
+
+	if _, err := db.ExecContext(context.Background(), "DELETE FROM sessions"); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.QueryContext(context.Background(), "SELECT id FROM users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = rows.Close()
+
+	spans := r.Spans()
+	want := []string{"SQL.Prepare", "SQL.Exec", "SQL.Prepare", "SQL.Query"}
+	if got := spans.Names(); !slices.Equal(got, want) {
+		t.Errorf("spans = %v, want %v", got, want)
+	}
+	if n := len(started.Started()); n != spans.Len() {
+		t.Errorf("%d spans started, want the %d ended ones", n, spans.Len())
+	}
+}
+
+func TestTransactionSpans(t *testing.T) {
+	db, r := openFake(t, "")
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec("DELETE FROM sessions"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := r.Spans()
+	if got := spans.Names(); len(got) != 3 || got[0] != "SQL.Begin" || got[1] != "SQL.Exec" || got[2] != "SQL.Commit" {
+		t.Errorf("spans = %v, want [SQL.Begin SQL.Exec SQL.Commit]", got)
+	}
+	r.Find("SQL.Commit").HasAttribute("db.operation", "Commit").HasStatus(codes.Ok)
+}
+
+func TestMySQLDialect(t *testing.T) {
+	db, r := openFake(t, "", sqlotel.WithDBSystem("mysql"))
+
+	if _, err := db.ExecContext(context.Background(), `UPDATE users SET bio = 'it\'s' WHERE pw = "hunter2"`); err != nil {
+		t.Fatal(err)
+	}
+
+	r.Find("SQL.Exec").
+		HasAttribute("db.system", "mysql").
+		HasAttribute("db.statement", "UPDATE users SET bio = ? WHERE pw = ?")
+}
@@ -0,0 +1,106 @@
+package sqlotel
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the spans and metrics of the package
+const ScopeName = "database/sql"
+
+// defaultMaxStatementLength is used when WithMaxStatementLength is not set
+const defaultMaxStatementLength = 2048
+
+// Option configures the instrumentation of a driver or a DB
+type Option func(*config)
+
+type config struct {
+	tracerProvider     trace.TracerProvider
+	meterProvider      metric.MeterProvider
+	system             string
+	name               string
+	maxStatementLength int
+	disableStatements  bool
+}
+
+// WithTracerProvider sets the tracer provider of the spans, the global provider
+// is used by default
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider sets the meter provider of the metrics, the global provider
+// is used by default
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = provider
+	}
+}
+
+// WithDBSystem sets db.system, e.g. "postgresql" or "mysql". Open derives it
+// from the driver name when it is not set.
+func WithDBSystem(system string) Option {
+	return func(c *config) {
+		c.system = system
+	}
+}
+
+// WithDBName sets db.name, the database the connections use
+func WithDBName(name string) Option {
+	return func(c *config) {
+		c.name = name
+	}
+}
+
+// WithMaxStatementLength sets the maximum length of db.statement, longer
+// statements are truncated. The default is 2048.
+func WithMaxStatementLength(length int) Option {
+	return func(c *config) {
+		c.maxStatementLength = length
+	}
+}
+
+// WithoutStatements stops recording db.statement, the operation and the query
+// shape hash are still recorded
+func WithoutStatements() Option {
+	return func(c *config) {
+		c.disableStatements = true
+	}
+}
+
+// newConfig applies opts over the defaults
+func newConfig(opts []Option) config {
+	c := config{
+		tracerProvider:     otel.GetTracerProvider(),
+		meterProvider:      otel.GetMeterProvider(),
+		system:             "other_sql",
+		maxStatementLength: defaultMaxStatementLength,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// systemFromDriverName maps common driver names to db.system values
+func systemFromDriverName(driverName string) string {
+	switch driverName {
+	case "postgres", "pgx", "pgx/v5", "cloudsqlpostgres":
+		return "postgresql"
+	case "mysql":
+		return "mysql"
+	case "sqlite", "sqlite3":
+		return "sqlite"
+	case "sqlserver", "mssql":
+		return "mssql"
+	case "oracle", "godror", "oci8":
+		return "oracle"
+	case "clickhouse":
+		return "clickhouse"
+	default:
+		return "other_sql"
+	}
+}
@@ -0,0 +1,282 @@
+package sqlotel
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// Statement is a sanitized SQL statement
+// Text: the statement with literal values replaced by "?", truncated to the maximum length
+// ShapeHash: a stable hash of the full sanitized statement, equal for queries of the same shape
+// Operation: the first keyword of the statement, e.g. SELECT
+type Statement struct {
+	Text      string
+	ShapeHash string
+	Operation string
+}
+
+// Dialect selects the lexical rules of the strings of a query
+type Dialect int
+
+const (
+	// DialectStandard follows standard SQL and PostgreSQL: '' escapes a quote,
+	// backslashes only escape in E'...' strings and $tag$...$tag$ is a string
+	DialectStandard Dialect = iota
+	// DialectMySQL follows MySQL: backslashes escape in every string and
+	// "..." is a string, not an identifier
+	DialectMySQL
+)
+
+// dialectFromSystem returns the dialect of a db.system value
+func dialectFromSystem(system string) Dialect {
+	switch system {
+	case "mysql", "clickhouse":
+		return DialectMySQL
+	default:
+		return DialectStandard
+	}
+}
+
+// SanitizeQuery replaces the string and number literals of a query with "?",
+// removes comments, collapses whitespace and reduces lists of placeholders such
+// as IN (?, ?, ?) to IN (?), so queries differing only by their values have the
+// same shape. Identifiers, keywords and bind parameters ($1, ?, :name) are kept.
+// Strings follow DialectStandard, see SanitizeQueryDialect.
+func SanitizeQuery(query string, maxLength int) Statement {
+	return SanitizeQueryDialect(query, maxLength, DialectStandard)
+}
+
+// SanitizeQueryDialect is SanitizeQuery with the string rules of dialect
+func SanitizeQueryDialect(query string, maxLength int, dialect Dialect) Statement {
+	var sb strings.Builder
+	sb.Grow(len(query))
+	space := false
+	writeSpace := func() {
+		if space && sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		space = false
+	}
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			space = true
+		case c == '-' && i+1 < len(query) && query[i+1] == '-':
+			// Line comment
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+			space = true
+		case c == '/' && i+1 < len(query) && query[i+1] == '*':
+			// Block comment
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				i = len(query)
+			} else {
+				i += end + 3
+			}
+			space = true
+		case c == '\'' || (c == '"' && dialect == DialectMySQL):
+			i = stringEnd(query, i, dialect == DialectMySQL)
+			writeSpace()
+			sb.WriteByte('?')
+		case (c == 'E' || c == 'e') && i+1 < len(query) && query[i+1] == '\'' && !followsIdentifier(query, i):
+			// PostgreSQL escape string, the only one where backslashes escape
+			i = stringEnd(query, i+1, true)
+			writeSpace()
+			sb.WriteByte('?')
+		case c == '$' && dialect == DialectStandard && !followsIdentifier(query, i) && dollarTagLength(query[i:]) > 0:
+			// Dollar-quoted string, the body ends at the same $tag$
+			tag := query[i : i+dollarTagLength(query[i:])]
+			end := strings.Index(query[i+len(tag):], tag)
+			if end < 0 {
+				i = len(query)
+			} else {
+				i += len(tag) + end + len(tag) - 1
+			}
+			writeSpace()
+			sb.WriteByte('?')
+		case c == '"' || c == '`':
+			// Quoted identifier
+			end := strings.IndexByte(query[i+1:], c)
+			writeSpace()
+			if end < 0 {
+				sb.WriteString(query[i:])
+				i = len(query)
+				continue
+			}
+			sb.WriteString(query[i : i+end+2])
+			i += end + 1
+		case isDigit(c) && (space || !isIdentifierByte(previousByte(&sb))):
+			// Number literal, not part of an identifier or of a $1 parameter
+			if c == '0' && i+1 < len(query) && (query[i+1] == 'x' || query[i+1] == 'X') {
+				i += 2
+				for i+1 < len(query) && isHexDigit(query[i+1]) {
+					i++
+				}
+			}
+			for i+1 < len(query) && (isDigit(query[i+1]) || query[i+1] == '.' || query[i+1] == 'e' || query[i+1] == 'E') {
+				i++
+			}
+			writeSpace()
+			sb.WriteByte('?')
+		default:
+			writeSpace()
+			sb.WriteByte(c)
+		}
+	}
+
+	shape := collapseLists(sb.String())
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(shape))
+
+	operation := shape
+	if i := strings.IndexAny(operation, " (;"); i >= 0 {
+		operation = operation[:i]
+	}
+
+	if maxLength <= 0 {
+		maxLength = defaultMaxStatementLength
+	}
+	text := shape
+	if len(text) > maxLength {
+		text = text[:maxLength] + "..."
+	}
+
+	return Statement{
+		Text:      text,
+		ShapeHash: fmt.Sprintf("%016x", hash.Sum64()),
+		Operation: strings.ToUpper(operation),
+	}
+}
+
+// stringEnd returns the index of the quote closing the string opened at start.
+// A doubled quote escapes it, and so does a backslash when backslashEscapes is set.
+func stringEnd(query string, start int, backslashEscapes bool) int {
+	quote := query[start]
+	i := start + 1
+	for ; i < len(query); i++ {
+		if query[i] == '\\' && backslashEscapes {
+			i++
+			continue
+		}
+		if query[i] == quote {
+			if i+1 < len(query) && query[i+1] == quote {
+				i++
+				continue
+			}
+			break
+		}
+	}
+	return i
+}
+
+// dollarTagLength returns the length of the $tag$ or $$ delimiter at the start of
+// s, or 0. A tag does not start with a digit, so $1 parameters are not delimiters.
+func dollarTagLength(s string) int {
+	for n := 1; n < len(s); n++ {
+		c := s[n]
+		switch {
+		case c == '$':
+			return n + 1
+		case c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (isDigit(c) && n > 1):
+		default:
+			return 0
+		}
+	}
+	return 0
+}
+
+// followsIdentifier reports whether the byte at i continues a name, e.g. the e
+// of "name'" or the $ of "a$b"
+func followsIdentifier(query string, i int) bool {
+	return i > 0 && isIdentifierByte(query[i-1])
+}
+
+// collapseLists reduces lists of placeholders, e.g. "(?, ?, ?)" and "($1, $2)",
+// to a single one, so IN lists and multi-row VALUES of any size have the same shape
+func collapseLists(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		sb.WriteByte(s[i])
+		if s[i] != '(' {
+			continue
+		}
+
+		// Find the closing parenthesis of a list made only of placeholders
+		j := i + 1
+		items := 0
+		for j < len(s) {
+			n := placeholderLength(s[j:])
+			if n == 0 {
+				break
+			}
+			items++
+			j += n
+			for j < len(s) && s[j] == ' ' {
+				j++
+			}
+			if j < len(s) && s[j] == ',' {
+				j++
+				for j < len(s) && s[j] == ' ' {
+					j++
+				}
+				continue
+			}
+			break
+		}
+		if items > 1 && j < len(s) && s[j] == ')' {
+			sb.WriteByte('?')
+			i = j - 1
+		}
+	}
+	return sb.String()
+}
+
+// placeholderLength returns the length of the placeholder at the start of s,
+// "?" or "$n", or 0
+func placeholderLength(s string) int {
+	if s == "" {
+		return 0
+	}
+	if s[0] == '?' {
+		return 1
+	}
+	if s[0] == '$' {
+		n := 1
+		for n < len(s) && isDigit(s[n]) {
+			n++
+		}
+		if n > 1 {
+			return n
+		}
+	}
+	return 0
+}
+
+// previousByte returns the last byte written to sb, 0 when empty
+func previousByte(sb *strings.Builder) byte {
+	s := sb.String()
+	if s == "" {
+		return 0
+	}
+	return s[len(s)-1]
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// isIdentifierByte reports whether c can precede a digit inside a name or a
+// parameter, e.g. table1 or $1
+func isIdentifierByte(c byte) bool {
+	return c == '_' || c == '$' || c == ':' || c == '@' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
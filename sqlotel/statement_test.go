@@ -0,0 +1,117 @@
+package sqlotel_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Doraverse-Workspace/open-observe/sqlotel"
+)
+
+func TestSanitizeQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "literals",
+			query: "SELECT * FROM users WHERE email = 'ada@example.com' AND age > 30 AND score < 1.5e3",
+			want:  "SELECT * FROM users WHERE email = ? AND age > ? AND score < ?",
+		},
+		{
+			name:  "doubled quote",
+			query: "SELECT * FROM t WHERE name = 'O''Brien' AND pw = 'hunter2'",
+			want:  "SELECT * FROM t WHERE name = ? AND pw = ?",
+		},
+		{
+			name:  "backslash is not an escape",
+			query: `SELECT * FROM t WHERE path = 'C:\' AND pw = 'hunter2' LIMIT 1`,
+			want:  "SELECT * FROM t WHERE path = ? AND pw = ? LIMIT ?",
+		},
+		{
+			name:  "escape string",
+			query: `SELECT * FROM t WHERE a = E'it\'s' AND b = e'\\' AND pw = 'hunter2'`,
+			want:  "SELECT * FROM t WHERE a = ? AND b = ? AND pw = ?",
+		},
+		{
+			name:  "dollar quoted",
+			query: "SELECT $$secret$$",
+			want:  "SELECT ?",
+		},
+		{
+			name:  "tagged dollar quoted",
+			query: "SELECT $fn$ it's $$ still $fn$, $1 FROM t WHERE id = $2",
+			want:  "SELECT ?, $1 FROM t WHERE id = $2",
+		},
+		{
+			name:  "unterminated dollar quote",
+			query: "SELECT $$secret",
+			want:  "SELECT ?",
+		},
+		{
+			name:  "identifiers and parameters",
+			query: `SELECT "Users".id1, col_2 FROM "Users" WHERE a = :name AND b = @p1 AND c = 0x1F`,
+			want:  `SELECT "Users".id1, col_2 FROM "Users" WHERE a = :name AND b = @p1 AND c = ?`,
+		},
+		{
+			name:  "lists",
+			query: "SELECT * FROM t WHERE id IN (1, 2, 3) AND code IN ($1, $2)",
+			want:  "SELECT * FROM t WHERE id IN (?) AND code IN (?)",
+		},
+		{
+			name:  "comments and whitespace",
+			query: "SELECT id -- the id\nFROM   t /* all\nrows */ WHERE\tx = 1",
+			want:  "SELECT id FROM t WHERE x = ?",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sqlotel.SanitizeQuery(tt.query, 0).Text; got != tt.want {
+				t.Errorf("SanitizeQuery(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeQueryMySQL(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{
+			query: `SELECT * FROM t WHERE a = 'it\'s' AND pw = 'hunter2'`,
+			want:  "SELECT * FROM t WHERE a = ? AND pw = ?",
+		},
+		{
+			query: `SELECT * FROM t WHERE path = 'C:\\' AND pw = "hunter2"`,
+			want:  "SELECT * FROM t WHERE path = ? AND pw = ?",
+		},
+		{
+			query: "SELECT `order` FROM t WHERE id = ?",
+			want:  "SELECT `order` FROM t WHERE id = ?",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := sqlotel.SanitizeQueryDialect(tt.query, 0, sqlotel.DialectMySQL).Text; got != tt.want {
+			t.Errorf("SanitizeQueryDialect(%q, DialectMySQL) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSanitizeQueryShape(t *testing.T) {
+	a := sqlotel.SanitizeQuery("select * from t where id in (1, 2, 3)", 0)
+	b := sqlotel.SanitizeQuery("select *  from t where id in (4)", 0)
+	if a.ShapeHash != b.ShapeHash {
+		t.Errorf("shape hashes %s and %s differ, want equal", a.ShapeHash, b.ShapeHash)
+	}
+	if a.Operation != "SELECT" {
+		t.Errorf("Operation = %q, want SELECT", a.Operation)
+	}
+
+	long := sqlotel.SanitizeQuery("SELECT "+strings.Repeat("a", 100)+" FROM t", 10)
+	if long.Text != "SELECT aaa..." {
+		t.Errorf("Text = %q, want the first 10 bytes and ...", long.Text)
+	}
+}
@@ -0,0 +1,87 @@
+package sqlotel
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// RecordStats reports the connection pool statistics of db (sql.DBStats) as
+// metrics, read at every collection of the meter provider. poolName tells the
+// pools of a process apart, e.g. the database name. Call Unregister on the
+// returned registration when db is closed.
+func RecordStats(db *sql.DB, poolName string, opts ...Option) (metric.Registration, error) {
+	cfg := newConfig(opts)
+	meter := cfg.meterProvider.Meter(ScopeName)
+
+	count, err := meter.Int64ObservableUpDownCounter(
+		"db.client.connection.count",
+		metric.WithDescription("The number of connections that are currently in state described by the state attribute."),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create db.client.connection.count: %w", err)
+	}
+	maxOpen, err := meter.Int64ObservableUpDownCounter(
+		"db.client.connection.max",
+		metric.WithDescription("The maximum number of open connections allowed, 0 when unlimited."),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create db.client.connection.max: %w", err)
+	}
+	waitCount, err := meter.Int64ObservableCounter(
+		"db.client.connection.wait_count",
+		metric.WithDescription("The total number of connections waited for."),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create db.client.connection.wait_count: %w", err)
+	}
+	waitTime, err := meter.Float64ObservableCounter(
+		"db.client.connection.wait_time",
+		metric.WithDescription("The total time blocked waiting for a new connection."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create db.client.connection.wait_time: %w", err)
+	}
+	closed, err := meter.Int64ObservableCounter(
+		"db.client.connection.closed",
+		metric.WithDescription("The total number of connections closed, by reason."),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create db.client.connection.closed: %w", err)
+	}
+
+	pool := attribute.NewSet(
+		attribute.String("db.system", cfg.system),
+		attribute.String("db.client.connection.pool.name", poolName),
+	)
+	withPool := func(kv ...attribute.KeyValue) metric.MeasurementOption {
+		return metric.WithAttributes(append(pool.ToSlice(), kv...)...)
+	}
+	idle := withPool(attribute.String("db.client.connection.state", "idle"))
+	used := withPool(attribute.String("db.client.connection.state", "used"))
+	maxIdle := withPool(attribute.String("reason", "max_idle"))
+	maxIdleTime := withPool(attribute.String("reason", "max_idle_time"))
+	maxLifetime := withPool(attribute.String("reason", "max_lifetime"))
+	poolOnly := metric.WithAttributeSet(pool)
+
+	return meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		stats := db.Stats()
+		o.ObserveInt64(count, int64(stats.Idle), idle)
+		o.ObserveInt64(count, int64(stats.InUse), used)
+		o.ObserveInt64(maxOpen, int64(stats.MaxOpenConnections), poolOnly)
+		o.ObserveInt64(waitCount, stats.WaitCount, poolOnly)
+		o.ObserveFloat64(waitTime, stats.WaitDuration.Seconds(), poolOnly)
+		o.ObserveInt64(closed, stats.MaxIdleClosed, maxIdle)
+		o.ObserveInt64(closed, stats.MaxIdleTimeClosed, maxIdleTime)
+		o.ObserveInt64(closed, stats.MaxLifetimeClosed, maxLifetime)
+		return nil
+	}, count, maxOpen, waitCount, waitTime, closed)
+}